@@ -12,16 +12,37 @@ import (
 
 type (
 	Ast struct {
-		root *Script
+		root     *Script
+		comments commentGroup
+		// program is set for trees where the root commands
+		// are not wrapped in a block
+		program bool
 	}
 
 	Cmd struct {
 		command Symbol
-		args    []Argument
+		// expr is set for commands written as an
+		// expression, they take no arguments
+		expr *Expression
+		// indirect is set when the command is the
+		// value of a variable ($f 1 2)
+		indirect bool
+		args     []Argument
+		flags    []Flag
+		// flagPos keeps the number of arguments which were
+		// written before each flag
+		flagPos  []int
+		comments commentGroup
+
+		span     Span
+		argSpans []Span
 	}
 
 	Script struct {
 		cmds []*Cmd
+		// comments found after the last command
+		comments []Comment
+		span     Span
 	}
 
 	Text struct {
@@ -37,11 +58,14 @@ type (
 	}
 
 	Var struct {
-		sym Symbol
+		sym  Symbol
+		span Span
 	}
 
 	List struct {
-		data *pdata.ArgumentListSlice
+		data      *pdata.ArgumentListSlice
+		span      Span
+		itemSpans []Span
 	}
 
 	Formatter interface {
@@ -58,7 +82,7 @@ var (
 	// this should be kept in sync with the parser rules
 	// otherwise the package won't be able to create the Symbol node
 	// TODO: think of a way to avoid this duplicated re
-	symbolRe = regexp.MustCompile(`[\p{Ll}|\p{Lu}|!|?|\.|\\|-|+|*|&|^|%|#|@|~]+[\p{Ll}|\p{Lu}|!|?|\.|\\|-|+|*|&|^|%|$|#|@|~|0-9]*`)
+	symbolRe = regexp.MustCompile(`[\p{Ll}|\p{Lu}|!|?|\.|\\|-|+|*|&|^|%|#|@|~]+[\p{Ll}|\p{Lu}|!|?|\.|\\|-|+|*|&|^|%|$|#|@|~|/|0-9]*`)
 
 	errNotAValidSymbol = errors.New("not a valid symbol")
 )
@@ -77,8 +101,10 @@ func (n Number) Float64() float64 { return n.float64 }
 func (n Number) anchor()          {}
 func (n Number) String() string   { return strconv.FormatFloat(n.float64, 'f', -1, 64) }
 
+// Fmt writes the text as a double quoted string literal,
+// so it is never mistaken for a symbol or a number
 func (t Text) Fmt(p Printer) {
-	p.WriteString(t.string)
+	p.WriteString(quote(t.string))
 }
 func (t Text) Text() string   { return t.string }
 func (t Text) String() string { return t.string }
@@ -92,37 +118,63 @@ func (v Var) Fmt(p Printer) {
 func (v Var) Name() Symbol   { return v.sym }
 func (v Var) String() string { return "$" + v.sym.Text() }
 
+// Span returns where the variable was used, two uses of the
+// same variable are different values, compare them by Name
+func (v Var) Span() Span { return v.span }
+
+// WithSpan returns a copy of this variable with the given span
+func (v Var) WithSpan(sp Span) Var {
+	v.span = sp
+	return v
+}
+
+// Fmt writes the command and its trailing comments,
+// leading comments are written by the enclosing Script
 func (c *Cmd) Fmt(p Printer) {
+	if c.expr != nil {
+		c.expr.Fmt(p)
+		c.comments.fmtTrailing(p)
+		return
+	}
+	if c.indirect {
+		p.WriteString("$")
+	}
 	c.command.Fmt(p)
-	for _, a := range c.args {
+	flag := 0
+	fmtFlags := func(pos int) {
+		for ; flag < len(c.flags) && c.flagPos[flag] <= pos; flag++ {
+			p.WriteArgSeparator()
+			c.flags[flag].Fmt(p)
+		}
+	}
+	for i, a := range c.args {
+		fmtFlags(i)
 		if f, ok := a.(Formatter); ok {
 			p.WriteArgSeparator()
 			f.Fmt(p)
 		}
 	}
+	fmtFlags(len(c.args))
+	c.comments.fmtTrailing(p)
 }
 
 func (s *Script) Fmt(p Printer) {
-	p.WriteIndent()
 	p.WriteString("{")
-	switch len(s.cmds) {
-	case 0:
+	switch {
+	case len(s.cmds) == 0 && len(s.comments) == 0:
 		p.WriteString("}")
 		return
-	case 1:
+	case len(s.cmds) == 1 && len(s.comments) == 0 && s.cmds[0].comments.empty():
 		p.WriteString(" ")
 		s.cmds[0].Fmt(p)
 		p.WriteString(" }")
 		return
 	default:
+		// comments force the script to use one line per command
+		// otherwise a line comment would hide what follows it
 		p.WriteLineBreak()
 		p.Indent()
-
-		for _, c := range s.cmds {
-			p.WriteIndent()
-			c.Fmt(p)
-			p.WriteLineBreak()
-		}
+		s.fmtLines(p)
 		p.Unindent()
 		p.WriteIndent()
 		p.WriteString("}")
@@ -130,14 +182,45 @@ func (s *Script) Fmt(p Printer) {
 	}
 }
 
+// fmtLines writes each command (and comment) in its own line
+func (s *Script) fmtLines(p Printer) {
+	for _, c := range s.cmds {
+		p.WriteIndent()
+		c.comments.fmtLeading(p)
+		c.Fmt(p)
+		p.WriteLineBreak()
+	}
+	for _, c := range s.comments {
+		p.WriteIndent()
+		c.Fmt(p)
+		p.WriteLineBreak()
+	}
+}
+
 func (s *Script) anchor() {}
 
+func (s *Script) Span() Span { return s.span }
+
+func (s *Script) SetSpan(sp Span) *Script {
+	s.span = sp
+	return s
+}
+
 func (a *Ast) Fmt(p Printer) {
+	if a.program && a.root != nil {
+		a.root.fmtLines(p)
+		return
+	}
+	a.comments.fmtLeading(p)
 	if a.root == nil {
 		p.WriteString("{}")
-		return
+	} else {
+		a.root.Fmt(p)
+	}
+	for _, c := range a.comments.trailing {
+		p.WriteLineBreak()
+		c.Fmt(p)
 	}
-	a.root.Fmt(p)
 }
 
 func (a *Ast) Root() *Script { return a.root }
@@ -157,6 +240,38 @@ func (a *Ast) SetRoot(sc *Script) *Script {
 	return a.root
 }
 
+// SetProgram marks the tree as a program, whose root commands
+// are formatted without the surrounding block
+func (a *Ast) SetProgram(program bool) *Ast {
+	a.program = program
+	return a
+}
+
+// IsProgram returns true for trees parsed by parser.ParseProgram
+func (a *Ast) IsProgram() bool { return a.program }
+
+// AddLeadingComment registers comments written before the root script
+func (a *Ast) AddLeadingComment(c ...Comment) *Ast {
+	a.comments.leading = append(a.comments.leading, c...)
+	return a
+}
+
+// LeadingComments returns the comments written before the root script
+func (a *Ast) LeadingComments() []Comment {
+	return append([]Comment(nil), a.comments.leading...)
+}
+
+// AddTrailingComment registers comments written after the root script
+func (a *Ast) AddTrailingComment(c ...Comment) *Ast {
+	a.comments.trailing = append(a.comments.trailing, c...)
+	return a
+}
+
+// TrailingComments returns the comments written after the root script
+func (a *Ast) TrailingComments() []Comment {
+	return append([]Comment(nil), a.comments.trailing...)
+}
+
 func New() *Ast {
 	return &Ast{
 		root: NewScript(),
@@ -206,7 +321,7 @@ func ScopedSymbol(scope Symbol, id Symbol) Symbol {
 	}
 }
 
-func NewVar(sym Symbol) Var { return Var{sym} }
+func NewVar(sym Symbol) Var { return Var{sym: sym} }
 func NewVarString(s string) (Var, error) {
 	sym, err := NewSymbol(s)
 	return NewVar(sym), err
@@ -221,8 +336,20 @@ func (s *Script) Commands() []*Cmd {
 	return append([]*Cmd(nil), s.cmds...)
 }
 
+// AddComment registers comments found after the last command
+func (s *Script) AddComment(c ...Comment) *Script {
+	s.comments = append(s.comments, c...)
+	return s
+}
+
+// Comments returns the comments found after the last command
+func (s *Script) Comments() []Comment {
+	return append([]Comment(nil), s.comments...)
+}
+
 func (c *Cmd) SetCommand(s Symbol) *Cmd {
 	c.command = s
+	c.indirect = false
 	return c
 }
 
@@ -230,11 +357,92 @@ func (c *Cmd) Command() Symbol {
 	return c.command
 }
 
+// SetExpression makes the command evaluate e, which is
+// mostly used by the conditions of guard and switch
+func (c *Cmd) SetExpression(e *Expression) *Cmd {
+	c.expr = e
+	return c
+}
+
+// Expression returns the expression evaluated by the command
+// and true if the command was written as (= ...)
+func (c *Cmd) Expression() (*Expression, bool) {
+	return c.expr, c.expr != nil
+}
+
+// SetCommandVar makes the command call the value of v
+func (c *Cmd) SetCommandVar(v Var) *Cmd {
+	c.command = v.Name()
+	c.indirect = true
+	return c
+}
+
+// CommandVar returns the variable holding the command
+// and true if the command was written as $f
+func (c *Cmd) CommandVar() (Var, bool) {
+	return NewVar(c.command), c.indirect
+}
+
 func (c *Cmd) AddArgument(a Argument) *Cmd {
+	return c.AddArgumentAt(a, Span{})
+}
+
+// AddArgumentAt adds an argument found at the given span
+func (c *Cmd) AddArgumentAt(a Argument, sp Span) *Cmd {
 	c.args = append(c.args, a)
+	c.argSpans = append(c.argSpans, sp)
+	return c
+}
+
+// AddFlag adds a flag after the arguments added so far
+func (c *Cmd) AddFlag(f Flag) *Cmd {
+	c.flags = append(c.flags, f)
+	c.flagPos = append(c.flagPos, len(c.args))
+	return c
+}
+
+// Flags returns the flags given to the command
+func (c *Cmd) Flags() []Flag {
+	return append([]Flag(nil), c.flags...)
+}
+
+// ArgumentSpan returns the span of the i-th argument
+func (c *Cmd) ArgumentSpan(i int) Span {
+	if i < 0 || i >= len(c.argSpans) {
+		return Span{}
+	}
+	return c.argSpans[i]
+}
+
+func (c *Cmd) Span() Span { return c.span }
+
+func (c *Cmd) SetSpan(sp Span) *Cmd {
+	c.span = sp
 	return c
 }
 
 func (c *Cmd) Arguments() []Argument {
 	return append([]Argument(nil), c.args...)
 }
+
+// AddLeadingComment registers comments written in the lines
+// before the command
+func (c *Cmd) AddLeadingComment(cm ...Comment) *Cmd {
+	c.comments.leading = append(c.comments.leading, cm...)
+	return c
+}
+
+func (c *Cmd) LeadingComments() []Comment {
+	return append([]Comment(nil), c.comments.leading...)
+}
+
+// AddTrailingComment registers comments written in the same
+// line as the command
+func (c *Cmd) AddTrailingComment(cm ...Comment) *Cmd {
+	c.comments.trailing = append(c.comments.trailing, cm...)
+	return c
+}
+
+func (c *Cmd) TrailingComments() []Comment {
+	return append([]Comment(nil), c.comments.trailing...)
+}
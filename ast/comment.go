@@ -0,0 +1,58 @@
+package ast
+
+import "strings"
+
+type (
+	// Comment holds the text of a line (# ...) or
+	// block (#| ... |#) comment, including its markers
+	Comment struct {
+		text string
+	}
+
+	// comments found around a node, leading comments are
+	// written on their own lines while trailing comments
+	// are written on the same line as the node
+	commentGroup struct {
+		leading  []Comment
+		trailing []Comment
+	}
+)
+
+// NewComment returns a comment with the given text,
+// which must include the comment markers
+func NewComment(text string) Comment {
+	return Comment{text: text}
+}
+
+func (c Comment) Text() string   { return c.text }
+func (c Comment) String() string { return c.text }
+
+// IsBlock returns true for #| ... |# comments
+func (c Comment) IsBlock() bool {
+	return strings.HasPrefix(c.text, "#|")
+}
+
+func (c Comment) Fmt(p Printer) {
+	p.WriteString(c.text)
+}
+
+func (g *commentGroup) empty() bool {
+	return len(g.leading) == 0 && len(g.trailing) == 0
+}
+
+// fmtLeading writes each leading comment in its own line
+func (g *commentGroup) fmtLeading(p Printer) {
+	for _, c := range g.leading {
+		c.Fmt(p)
+		p.WriteLineBreak()
+		p.WriteIndent()
+	}
+}
+
+// fmtTrailing writes the trailing comments in the current line
+func (g *commentGroup) fmtTrailing(p Printer) {
+	for _, c := range g.trailing {
+		p.WriteArgSeparator()
+		c.Fmt(p)
+	}
+}
@@ -0,0 +1,165 @@
+package ast
+
+import "strings"
+
+type (
+	// Operator is one of the operators allowed inside
+	// an expression
+	Operator string
+
+	// Expression is an infix expression written as (= ...),
+	// its value is a Binary, Unary or a single operand
+	Expression struct {
+		value Argument
+		span  Span
+	}
+
+	// Binary applies an operator to two operands
+	Binary struct {
+		op          Operator
+		left, right Argument
+	}
+
+	// Unary applies an operator to a single operand
+	Unary struct {
+		op      Operator
+		operand Argument
+	}
+)
+
+const (
+	OpOr  = Operator("or")
+	OpAnd = Operator("and")
+	OpNot = Operator("not")
+
+	OpEq = Operator("==")
+	OpNe = Operator("!=")
+	OpLt = Operator("<")
+	OpLe = Operator("<=")
+	OpGt = Operator(">")
+	OpGe = Operator(">=")
+
+	OpAdd = Operator("+")
+	OpSub = Operator("-")
+	OpMul = Operator("*")
+	OpDiv = Operator("/")
+	OpMod = Operator("%")
+
+	// OpNeg is the unary minus
+	OpNeg = Operator("neg")
+)
+
+// Precedence of the operator, operators with a higher
+// precedence are applied first
+func (o Operator) Precedence() int {
+	switch o {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpNot:
+		return 3
+	case OpEq, OpNe, OpLt, OpLe, OpGt, OpGe:
+		return 4
+	case OpAdd, OpSub:
+		return 5
+	case OpMul, OpDiv, OpMod:
+		return 6
+	case OpNeg:
+		return 7
+	}
+	return 0
+}
+
+func (o Operator) String() string {
+	if o == OpNeg {
+		return "-"
+	}
+	return string(o)
+}
+
+// NewExpression returns an expression which evaluates value
+func NewExpression(value Argument) *Expression {
+	return &Expression{value: value}
+}
+
+func (e *Expression) anchor() {}
+
+// Value returns the root of the expression
+func (e *Expression) Value() Argument { return e.value }
+
+func (e *Expression) Span() Span { return e.span }
+
+func (e *Expression) SetSpan(sp Span) *Expression {
+	e.span = sp
+	return e
+}
+
+func (e *Expression) Fmt(p Printer) {
+	p.WriteString("(= ")
+	e.value.Fmt(p)
+	p.WriteString(")")
+}
+
+func (e *Expression) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	e.Fmt(p)
+	return buf.String()
+}
+
+func NewBinary(op Operator, left, right Argument) *Binary {
+	return &Binary{op: op, left: left, right: right}
+}
+
+func (b *Binary) anchor() {}
+
+func (b *Binary) Operator() Operator { return b.op }
+func (b *Binary) Left() Argument     { return b.left }
+func (b *Binary) Right() Argument    { return b.right }
+
+// Fmt writes the operands around the operator, parenthesis are
+// only added when required by the precedence of the operands
+func (b *Binary) Fmt(p Printer) {
+	fmtOperand(p, b.left, b.op.Precedence())
+	p.WriteString(" " + b.op.String() + " ")
+	// operators are left associative
+	fmtOperand(p, b.right, b.op.Precedence()+1)
+}
+
+func NewUnary(op Operator, operand Argument) *Unary {
+	return &Unary{op: op, operand: operand}
+}
+
+func (u *Unary) anchor() {}
+
+func (u *Unary) Operator() Operator { return u.op }
+func (u *Unary) Operand() Argument  { return u.operand }
+
+func (u *Unary) Fmt(p Printer) {
+	if u.op == OpNeg {
+		p.WriteString("-")
+	} else {
+		p.WriteString(u.op.String() + " ")
+	}
+	fmtOperand(p, u.operand, u.op.Precedence())
+}
+
+// fmtOperand writes a wrapped in parenthesis if its operator
+// has a precedence lower than min
+func fmtOperand(p Printer, a Argument, min int) {
+	prec := min
+	switch a := a.(type) {
+	case *Binary:
+		prec = a.op.Precedence()
+	case *Unary:
+		prec = a.op.Precedence()
+	}
+	if prec < min {
+		p.WriteString("(")
+		a.Fmt(p)
+		p.WriteString(")")
+		return
+	}
+	a.Fmt(p)
+}
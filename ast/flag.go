@@ -0,0 +1,64 @@
+package ast
+
+import "strings"
+
+type (
+	// Flag is a named argument given to a command, short flags
+	// (-v) are switches while long flags (--timeout=5) might
+	// have a value
+	Flag struct {
+		name  Symbol
+		short bool
+		value Argument
+		span  Span
+	}
+)
+
+// NewShortFlag returns a switch like -v
+func NewShortFlag(name Symbol) Flag {
+	return Flag{name: name, short: true}
+}
+
+// NewLongFlag returns a flag like --timeout=5, value
+// is nil for flags used as switches (--verbose)
+func NewLongFlag(name Symbol, value Argument) Flag {
+	return Flag{name: name, value: value}
+}
+
+// Name of the flag without the leading dashes
+func (f Flag) Name() Symbol { return f.name }
+
+// IsShort returns true for flags written with a single dash
+func (f Flag) IsShort() bool { return f.short }
+
+// Value returns the value of the flag or nil if it
+// is used as a switch
+func (f Flag) Value() Argument { return f.value }
+
+func (f Flag) Span() Span { return f.span }
+
+// WithSpan returns a copy of the flag with the given span
+func (f Flag) WithSpan(sp Span) Flag {
+	f.span = sp
+	return f
+}
+
+func (f Flag) Fmt(p Printer) {
+	if f.short {
+		p.WriteString("-")
+	} else {
+		p.WriteString("--")
+	}
+	f.name.Fmt(p)
+	if f.value != nil {
+		p.WriteString("=")
+		f.value.Fmt(p)
+	}
+}
+
+func (f Flag) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	f.Fmt(p)
+	return buf.String()
+}
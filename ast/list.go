@@ -8,6 +8,30 @@ import (
 
 func (l *List) anchor() {}
 
+// Span returns the region of the code which declared this list,
+// lists created at runtime do not have a valid span
+func (l *List) Span() Span { return l.span }
+
+// WithSpan returns a copy of this list with the given span
+func (l *List) WithSpan(sp Span) *List {
+	return &List{data: l.data, span: sp, itemSpans: l.itemSpans}
+}
+
+// ItemSpan returns the span of the i-th item, like Span
+// it is only valid for lists created by the parser
+func (l *List) ItemSpan(i int) Span {
+	if i < 0 || i >= len(l.itemSpans) {
+		return Span{}
+	}
+	return l.itemSpans[i]
+}
+
+// WithItemSpans returns a copy of this list with the
+// span of each item
+func (l *List) WithItemSpans(spans []Span) *List {
+	return &List{data: l.data, span: l.span, itemSpans: spans}
+}
+
 // Head returns the first value of this list
 func (l *List) Head() Argument {
 	if l.Nil() {
@@ -24,6 +48,11 @@ func (l *List) Tail() *List {
 	return &List{data: l.data.Slice(1, l.data.Len())}
 }
 
+// Len returns the number of items in the list
+func (l *List) Len() int {
+	return l.data.Len()
+}
+
 // Nil returns true if the list is empty
 func (l *List) Nil() bool {
 	return l.data.Len() == 0
@@ -0,0 +1,193 @@
+package ast
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/andrebq/gshell/internal/pdata"
+)
+
+type (
+	// Map is a persistent collection of key/value pairs, keys
+	// are usually a Symbol, Number or Text.
+	//
+	// Set and Delete return a new map which shares most of its
+	// memory with the original one, use NewMap to build a map
+	// from many pairs at once
+	Map struct {
+		data *pdata.ArgumentMap
+		span Span
+		// keys is shared by the copies of the map with
+		// different spans, as they have the same keys
+		keys *sortedKeys
+	}
+
+	// sortedKeys keeps the result of Map.Keys, as maps
+	// are usually printed or iterated more than once
+	sortedKeys struct {
+		once sync.Once
+		keys []Argument
+	}
+)
+
+var nilMap = &Map{data: pdata.NewArgumentMap(), keys: &sortedKeys{}}
+
+// NilMap returns the empty map
+func NilMap() *Map { return nilMap }
+
+// NewMap returns a map with a copy of the given pairs
+func NewMap(pairs map[Argument]Argument) *Map {
+	if len(pairs) == 0 {
+		return nilMap
+	}
+	items := make([]pdata.ArgumentMapItem, 0, len(pairs))
+	for k, v := range pairs {
+		items = append(items, pdata.ArgumentMapItem{Key: mapKey(k), Value: v})
+	}
+	return &Map{data: pdata.NewArgumentMap(items...), keys: &sortedKeys{}}
+}
+
+func (m *Map) anchor() {}
+
+// Span returns the region of the code which declared this map,
+// maps created at runtime do not have a valid span
+func (m *Map) Span() Span { return m.span }
+
+// WithSpan returns a copy of this map with the given span
+func (m *Map) WithSpan(sp Span) *Map {
+	return &Map{data: m.data, span: sp, keys: m.keys}
+}
+
+// Len returns the number of keys in the map
+func (m *Map) Len() int { return m.data.Len() }
+
+// Get returns the value associated with key
+func (m *Map) Get(key Argument) (Argument, bool) {
+	v, ok := m.data.Load(mapKey(key))
+	if !ok {
+		return nil, false
+	}
+	return v.(Argument), true
+}
+
+// Set returns a new map where key is associated with value
+func (m *Map) Set(key, value Argument) *Map {
+	return &Map{data: m.data.Store(mapKey(key), value), keys: &sortedKeys{}}
+}
+
+// Delete returns a new map without key
+func (m *Map) Delete(key Argument) *Map {
+	data := m.data.Delete(mapKey(key))
+	if data == m.data {
+		return m
+	}
+	return &Map{data: data, keys: &sortedKeys{}}
+}
+
+// Keys returns the keys of the map in their canonical order:
+// numbers, text, symbols and then variables, each group
+// sorted by their value
+func (m *Map) Keys() []Argument {
+	keys := m.sortedKeys()
+	return append(make([]Argument, 0, len(keys)), keys...)
+}
+
+// ForEach runs fn for every key/value pair using the order
+// from Keys, users should return false if they want to bail out
+// before processing the whole map.
+//
+// It returns the number of pairs that were iterated before
+// false was returned
+func (m *Map) ForEach(fn func(key, value Argument) bool) int {
+	var count int
+	for _, k := range m.sortedKeys() {
+		count++
+		v, _ := m.data.Load(k)
+		if !fn(k, v.(Argument)) {
+			break
+		}
+	}
+	return count
+}
+
+// Fmt prints the cannonical representation of this map
+func (m *Map) Fmt(p Printer) {
+	p.WriteString("%[")
+	m.ForEach(func(k, v Argument) bool {
+		p.WriteArgSeparator()
+		k.Fmt(p)
+		p.WriteArgSeparator()
+		v.Fmt(p)
+		return true
+	})
+	p.WriteArgSeparator()
+	p.WriteString("]")
+}
+
+// String takes the map and prints it as a string, which
+// conforms to the standard gshell formatting rules
+func (m *Map) String() string {
+	buf := bytes.Buffer{}
+	p := NewPrinter(&buf)
+	m.Fmt(p)
+	return buf.String()
+}
+
+// sortedKeys returns the keys of the map in their
+// canonical order, they are sorted only once
+func (m *Map) sortedKeys() []Argument {
+	m.keys.once.Do(func() {
+		keys := make([]Argument, 0, m.data.Len())
+		m.data.Range(func(k, _ pdata.Any) bool {
+			keys = append(keys, k.(Argument))
+			return true
+		})
+		sort.Slice(keys, func(i, j int) bool {
+			return lessKey(keys[i], keys[j])
+		})
+		m.keys.keys = keys
+	})
+	return m.keys.keys
+}
+
+// mapKey removes the span of variables, so the same
+// variable written in different places is the same key
+func mapKey(key Argument) Argument {
+	if v, ok := key.(Var); ok {
+		return v.WithSpan(Span{})
+	}
+	return key
+}
+
+func lessKey(a, b Argument) bool {
+	ra, rb := keyRank(a), keyRank(b)
+	if ra != rb {
+		return ra < rb
+	}
+	switch a := a.(type) {
+	case Number:
+		return a.Float64() < b.(Number).Float64()
+	case Text:
+		return a.Text() < b.(Text).Text()
+	case Symbol:
+		return a.Text() < b.(Symbol).Text()
+	case Var:
+		return a.Name().Text() < b.(Var).Name().Text()
+	}
+	return false
+}
+
+func keyRank(a Argument) int {
+	switch a.(type) {
+	case Number:
+		return 0
+	case Text:
+		return 1
+	case Symbol:
+		return 2
+	case Var:
+		return 3
+	}
+	return 4
+}
@@ -0,0 +1,40 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+)
+
+var _ Argument = NilMap()
+
+func TestMapIsPersistent(t *testing.T) {
+	name, port := MustNewSymbol("name"), MustNewSymbol("port")
+	m := NilMap().Set(port, NewNumber(8080)).Set(name, NewText("x"))
+	m2 := m.Set(port, NewNumber(80)).Delete(name)
+
+	if v, _ := m.Get(port); v != NewNumber(8080) {
+		t.Errorf("Set should not change the original map got %v", v)
+	}
+	if _, found := m2.Get(name); found {
+		t.Error("Delete should remove the key from the new map")
+	}
+	if NilMap().Len() != 0 || m.Len() != 2 || m2.Len() != 1 {
+		t.Errorf("Unexpected sizes %v %v %v", NilMap().Len(), m.Len(), m2.Len())
+	}
+
+	keys := NilMap().Set(name, NewNumber(1)).Set(NewText("b"), NewNumber(2)).Set(NewNumber(3), NewNumber(3)).Keys()
+	expected := []Argument{NewNumber(3), NewText("b"), name}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Keys should be %v got %v", expected, keys)
+	}
+	pairs := map[Argument]Argument{name: NewText("y")}
+	m3 := NewMap(pairs)
+	pairs[name] = NewText("z")
+	if v, _ := m3.Get(name); v != NewText("y") {
+		t.Errorf("NewMap should copy its pairs got %v", v)
+	}
+
+	if s := m.String(); s != `%[ name "x" port 8080 ]` {
+		t.Errorf("Unexpected format %v", s)
+	}
+}
@@ -6,6 +6,10 @@ import "github.com/andrebq/gshell/ast"
 
 type (
 	Condition func(*[]ast.Argument) bool
+
+	// FlagCondition works like Condition but for the
+	// flags given to a command
+	FlagCondition func(*[]ast.Flag) bool
 )
 
 // Apply the Condition to the given list of arguments
@@ -135,3 +139,130 @@ func AnySymbol(out *ast.Symbol) Condition {
 		return true
 	}
 }
+
+// Map returns the first ast.Argument as a Map
+func Map(out **ast.Map) Condition {
+	return func(args *[]ast.Argument) bool {
+		if len(*args) == 0 {
+			return false
+		}
+		m, ok := (*args)[0].(*ast.Map)
+		if !ok {
+			return false
+		}
+		*args = (*args)[1:]
+		*out = m
+		return true
+	}
+}
+
+// MapOf returns the first ast.Argument as a Map
+// if, and only if, condition returns true for all pairs
+func MapOf(out **ast.Map, condition func(key, value ast.Argument) bool) Condition {
+	return func(args *[]ast.Argument) bool {
+		if len(*args) == 0 {
+			return false
+		}
+		m, ok := (*args)[0].(*ast.Map)
+		if !ok {
+			return false
+		}
+		valid := true
+		m.ForEach(func(k, v ast.Argument) bool {
+			valid = valid && condition(k, v)
+			return valid
+		})
+		if !valid {
+			return false
+		}
+		*args = (*args)[1:]
+		*out = m
+		return true
+	}
+}
+
+// ApplyFlags applies the FlagCondition to the given list of flags
+func ApplyFlags(flags *[]ast.Flag, c FlagCondition) bool {
+	return c(flags)
+}
+
+// Flags takes a list of FlagConditions and applies them sequentially,
+// like Guard the list of flags is only updated if all of them are true
+func Flags(conditions ...FlagCondition) FlagCondition {
+	return func(flags *[]ast.Flag) bool {
+		aux := *flags
+		for _, c := range conditions {
+			if !c(&aux) {
+				return false
+			}
+		}
+		*flags = aux
+		return true
+	}
+}
+
+// Flag matches a flag called name which has a value, the value
+// is set to out and the flag is removed from the list
+func Flag(name ast.Symbol, out *ast.Argument) FlagCondition {
+	return func(flags *[]ast.Flag) bool {
+		idx := indexOfFlag(*flags, name)
+		if idx < 0 || (*flags)[idx].Value() == nil {
+			return false
+		}
+		*out = (*flags)[idx].Value()
+		*flags = removeFlag(*flags, idx)
+		return true
+	}
+}
+
+// OptionalFlag works like Flag but it is also true when
+// the flag is missing, in which case out is not changed
+func OptionalFlag(name ast.Symbol, out *ast.Argument) FlagCondition {
+	return func(flags *[]ast.Flag) bool {
+		if indexOfFlag(*flags, name) < 0 {
+			return true
+		}
+		return Flag(name, out)(flags)
+	}
+}
+
+// Switch sets out to true if a flag called name was given
+// without a value, it is also true when the flag is missing
+func Switch(name ast.Symbol, out *bool) FlagCondition {
+	return func(flags *[]ast.Flag) bool {
+		idx := indexOfFlag(*flags, name)
+		if idx < 0 {
+			*out = false
+			return true
+		}
+		if (*flags)[idx].Value() != nil {
+			return false
+		}
+		*out = true
+		*flags = removeFlag(*flags, idx)
+		return true
+	}
+}
+
+// NoFlags is true if all flags were consumed by the
+// previous conditions
+func NoFlags() FlagCondition {
+	return func(flags *[]ast.Flag) bool {
+		return len(*flags) == 0
+	}
+}
+
+func indexOfFlag(flags []ast.Flag, name ast.Symbol) int {
+	for i, f := range flags {
+		if f.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func removeFlag(flags []ast.Flag, idx int) []ast.Flag {
+	out := make([]ast.Flag, 0, len(flags)-1)
+	out = append(out, flags[:idx]...)
+	return append(out, flags[idx+1:]...)
+}
@@ -0,0 +1,37 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// Opaque holds a value created at runtime which does not have
+	// a literal syntax (ie.: a function), so it can be stored in
+	// lists and maps. It is printed as its fmt representation,
+	// which cannot be parsed back
+	Opaque struct {
+		value interface{}
+	}
+)
+
+// NewOpaque wraps value
+func NewOpaque(value interface{}) *Opaque {
+	return &Opaque{value: value}
+}
+
+func (o *Opaque) anchor() {}
+
+// Value returns the wrapped value
+func (o *Opaque) Value() interface{} { return o.value }
+
+func (o *Opaque) Fmt(p Printer) {
+	p.WriteString(fmt.Sprintf("%v", o.value))
+}
+
+func (o *Opaque) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	o.Fmt(p)
+	return buf.String()
+}
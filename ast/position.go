@@ -0,0 +1,59 @@
+package ast
+
+import "fmt"
+
+type (
+	// Position in the source code, lines and columns start at 1
+	Position struct {
+		Line   int
+		Column int
+	}
+
+	// Span is the region of the source code which
+	// produced a node
+	Span struct {
+		// Source identifies where the code came from,
+		// usually the name of a file
+		Source string
+		Start  Position
+		End    Position
+	}
+
+	// Spanned is implemented by the nodes which know
+	// where they were found in the source code.
+	//
+	// Values (Symbol, Number and Text) are compared by value
+	// so their spans are kept by the Cmd or List which uses them,
+	// see Cmd.ArgumentSpan and List.ItemSpan
+	Spanned interface {
+		Span() Span
+	}
+)
+
+// IsValid returns false for nodes that were not created by the parser
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	return fmt.Sprintf("%v:%v", p.Line, p.Column)
+}
+
+// IsValid returns false for nodes that were not created by the parser
+func (s Span) IsValid() bool { return s.Start.IsValid() }
+
+// String returns the start of the span as source:line:column
+func (s Span) String() string {
+	if s.Source == "" {
+		return "line " + s.Start.String()
+	}
+	return s.Source + ":" + s.Start.String()
+}
+
+// SpanOf returns the span of n if it is known
+func SpanOf(n interface{}) (Span, bool) {
+	sp, ok := n.(Spanned)
+	if !ok {
+		return Span{}, false
+	}
+	s := sp.Span()
+	return s, s.IsValid()
+}
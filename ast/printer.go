@@ -1,7 +1,9 @@
 package ast
 
 import (
+	"fmt"
 	"io"
+	"strings"
 )
 
 type (
@@ -66,3 +68,40 @@ func (p *printer) WriteInlineTerminator() {
 func (p *printer) Err() error {
 	return p.err
 }
+
+// quote returns s as a double quoted string literal using
+// only the escape sequences understood by the parser
+func quote(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	escapeText(&buf, s)
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// escapeText writes s escaping anything that would be interpreted
+// by the parser inside a double quoted string
+func escapeText(buf *strings.Builder, s string) {
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '$':
+			buf.WriteString(`\$`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(buf, `\u%04x`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package ast
+
+import "strings"
+
+type (
+	// Spread marks an argument whose items are passed
+	// as separate arguments: $args... or [ 1 2 ]...
+	Spread struct {
+		value Argument
+	}
+)
+
+// NewSpread returns the spread of value
+func NewSpread(value Argument) *Spread {
+	return &Spread{value: value}
+}
+
+func (s *Spread) anchor() {}
+
+// Value returns the argument which should produce
+// the list of items
+func (s *Spread) Value() Argument { return s.value }
+
+func (s *Spread) Fmt(p Printer) {
+	s.value.Fmt(p)
+	p.WriteString("...")
+}
+
+func (s *Spread) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	s.Fmt(p)
+	return buf.String()
+}
@@ -0,0 +1,43 @@
+package ast
+
+import "strings"
+
+type (
+	// Substitution is a command wrapped in '(' ')' which is used
+	// as an argument, the command is evaluated and replaced by
+	// the value it returns
+	Substitution struct {
+		cmd  *Cmd
+		span Span
+	}
+)
+
+// NewSubstitution returns a substitution of the given command
+func NewSubstitution(cmd *Cmd) *Substitution {
+	return &Substitution{cmd: cmd}
+}
+
+func (s *Substitution) anchor() {}
+
+// Command returns the command which produces the value
+func (s *Substitution) Command() *Cmd { return s.cmd }
+
+func (s *Substitution) Span() Span { return s.span }
+
+func (s *Substitution) SetSpan(sp Span) *Substitution {
+	s.span = sp
+	return s
+}
+
+func (s *Substitution) Fmt(p Printer) {
+	p.WriteString("(")
+	s.cmd.Fmt(p)
+	p.WriteString(")")
+}
+
+func (s *Substitution) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	s.Fmt(p)
+	return buf.String()
+}
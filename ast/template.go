@@ -0,0 +1,87 @@
+package ast
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	// Template is a double quoted string which interpolates
+	// variables and command blocks, its parts are
+	// Text, Var or *Script values
+	Template struct {
+		parts []Argument
+		span  Span
+	}
+)
+
+// NewTemplate returns an empty template
+func NewTemplate() *Template {
+	return &Template{}
+}
+
+func (t *Template) anchor() {}
+
+// AddPart appends a Text, Var or *Script to the template
+func (t *Template) AddPart(a Argument) *Template {
+	t.parts = append(t.parts, a)
+	return t
+}
+
+func (t *Template) Span() Span { return t.span }
+
+func (t *Template) SetSpan(sp Span) *Template {
+	t.span = sp
+	return t
+}
+
+// Parts returns a copy of the parts of this template
+func (t *Template) Parts() []Argument {
+	return append([]Argument(nil), t.parts...)
+}
+
+// Fmt prints the template as a double quoted string, variables
+// are wrapped in ${} only when followed by text that would
+// otherwise be taken as part of the variable name, a '$' always
+// ends the name of a variable
+func (t *Template) Fmt(p Printer) {
+	var buf strings.Builder
+	buf.WriteString(`"`)
+	for i, part := range t.parts {
+		switch part := part.(type) {
+		case Text:
+			escapeText(&buf, part.Text())
+		case Var:
+			if i+1 < len(t.parts) && startsWithSymbolTail(t.parts[i+1]) {
+				buf.WriteString("${" + part.Name().Text() + "}")
+			} else {
+				buf.WriteString(part.String())
+			}
+		default:
+			p.WriteString(buf.String())
+			buf.Reset()
+			p.WriteString("$")
+			part.Fmt(p)
+		}
+	}
+	buf.WriteString(`"`)
+	p.WriteString(buf.String())
+}
+
+func (t *Template) String() string {
+	buf := strings.Builder{}
+	p := NewPrinter(&buf)
+	t.Fmt(p)
+	return buf.String()
+}
+
+func startsWithSymbolTail(a Argument) bool {
+	text, ok := a.(Text)
+	if !ok {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(text.Text())
+	return unicode.IsLower(r) || unicode.IsUpper(r) || unicode.Is(unicode.Nd, r) ||
+		strings.ContainsRune("|!?.-+*&^%#@~/", r)
+}
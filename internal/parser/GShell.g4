@@ -1,23 +1,59 @@
+// This grammar is documentation only, no code is generated from it.
+// The language is parsed by lexer.go and parse.go, keep them in sync
+// when changing the syntax. legacy/GShell.g4 is a frozen copy used to
+// generate the old ANTLR parser.
+
 grammar GShell;
 
 // Tokens
 fragment LETTER: [\p{Ll}|\p{Lu}];
 fragment DIGIT: [\p{Nd}];
-fragment PUNCTUATION_HEAD: [!?.\-+*&^%#@~];
-fragment PUCTUATION_TAIL: PUNCTUATION_HEAD | [$];
+fragment PUNCTUATION_HEAD: [!?.\-+*&^%@~];
+// '/' separates the module from the name of a function: strings/upper
+fragment PUCTUATION_TAIL: PUNCTUATION_HEAD | [$#/];
 fragment INT: '-' DIGIT+ | DIGIT+;
 fragment FLOAT: INT '.' DIGIT+;
 fragment IDENTIFER_START: LETTER|PUNCTUATION_HEAD;
 fragment IDENTIFIER_TAIL: (DIGIT|LETTER|PUCTUATION_TAIL);
-
+fragment BRACED: '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* '}';
+fragment HEX: [0-9a-fA-F];
+fragment VALID_ESCAPE: '\\' ([ntr"$\\] | 'u' HEX HEX HEX HEX);
+// the prefix of an escape sequence cut by the end of the input
+fragment OPEN_ESCAPE: '\\' ('u' HEX? HEX? HEX?)?;
+fragment OPEN_STRING: '"' (VALID_ESCAPE | '$' BRACED | ~["\\])* (OPEN_ESCAPE | '$' OPEN_BRACED)?;
+fragment OPEN_RAW_STRING: '\'' ~[']*;
+fragment OPEN_BRACED: '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* (OPEN_BRACED | OPEN_STRING | OPEN_RAW_STRING)?;
+
+// -v and --name are switches while --name=value takes the next
+// argument as its value, flags are recorded apart from the other
+// arguments of a command
+FLAG: '-' '-'? LETTER IDENTIFIER_TAIL* | '--' LETTER IDENTIFIER_TAIL* '=';
 IDENTIFIER: IDENTIFER_START IDENTIFIER_TAIL*;
 NUMBER: INT | FLOAT;
+// $name, ${name} and ${ commands... } inside a STRING are
+// interpolated, the parser splits the string into an ast.Template.
+// Inside a STRING the name of a variable ends at '$' ("$a$b" is $a then $b).
+// Escape sequences are checked by the parser, so an invalid
+// one is reported once instead of breaking the whole string
+STRING: '"' ('\\' . | '$' BRACED | ~["\\])* '"';
+RAW_STRING: '\'' ~[']* '\'';
+// a string cut by the end of the input is reported by the parser,
+// more input could still close it. One with an invalid escape
+// sequence is a lexer error as no input can fix it
+UNTERMINATED_STRING: (OPEN_STRING | OPEN_RAW_STRING) EOF;
 
 TERMINATOR: [;];
 NL: [\n];
 
 WS: [ \r\t]+ -> skip;
 
+// comments are attached to the closest command or script
+// by the parser, so the formatter can write them back
+BLOCK_COMMENT: '#|' .*? '|#' -> channel(HIDDEN);
+// reported as an error by the parser
+UNTERMINATED_COMMENT: '#|' (~'|' | '|'+ ~[|#])* '|'* EOF -> channel(HIDDEN);
+LINE_COMMENT: '#' (~[|\n] ~[\n]*)? -> channel(HIDDEN);
+
 // Rules
 start
    : script EOF;
@@ -27,7 +63,7 @@ terminator
    | NL ;
 
 commandListItem
-   : NL* singleCommand terminator NL*;
+   : singleCommand (terminator NL*)? ;
 
 openBlock
    : '{';
@@ -36,41 +72,73 @@ closeBlock
    : '}';
 
 commandBlock
-   //: openBlock commandListItem* closeBlock
-   : openBlock commandBlockTail;
-
-commandBlockTail
-   : NL* singleCommand terminator NL* commandBlockTail
-   | NL* singleCommand NL* closeBlock
-   | closeBlock;
+   : openBlock NL* commandListItem* closeBlock ;
 
 script
-   : commandBlock EOF;
+   : NL* commandBlock NL* EOF;
 
-singleCommand
-   : commandLine ;
+// entry rule for files, where top-level commands
+// are not wrapped in a block
+program
+   : NL* commandListItem* EOF;
 
-commandLine
-   : commandName
-   | commandName arguments ;
-
-commandName : IDENTIFIER ;
-
-arguments
-   : namedArgument arguments*
-   | numericArgument arguments*
-   | variableArgument arguments*
-   | scriptArgument arguments*
-   | listArgument arguments*;
+// an expression used as a command takes no arguments
+singleCommand
+   : expressionArgument
+   | commandName argument* ;
+
+// '$' IDENTIFIER calls the value of the variable
+commandName : IDENTIFIER | FLAG | '$' IDENTIFIER ;
+
+argument
+   : namedArgument
+   | flagArgument
+   | numericArgument
+   | textArgument
+   | variableArgument
+   | spreadArgument
+   | scriptArgument
+   | listArgument
+   | mapArgument
+   | substitutionArgument
+   | expressionArgument ;
 
 namedArgument : IDENTIFIER ;
+// only flags ending with '=' take a value, inside
+// lists and maps flags are read as symbols
+flagArgument : FLAG argument? ;
 numericArgument : NUMBER ;
+textArgument : STRING | RAW_STRING | UNTERMINATED_STRING ;
 variableArgument : '$' IDENTIFIER ;
+// the items of the list are passed as separate arguments, there is
+// no space before '...' ($args... is read as a single IDENTIFIER).
+// Only allowed in the arguments of a command and in lists
+spreadArgument
+   : variableArgument '...'
+   | listArgument '...'
+   | substitutionArgument '...' ;
 scriptArgument: commandBlock ;
-listArgument: '[' arguments ']' ;
-
-// expression
-//    : expression op=('*'|'/') expression # MulDiv
-//    | expression op=('+'|'-') expression # AddSub
-//    | NUMBER                             # Number
-//    ;
+listArgument: '[' (argument | NL)* ']' ;
+// keys are unique
+mapArgument: '%[' NL* (mapKey NL* argument NL*)* ']' ;
+mapKey: namedArgument | numericArgument | textArgument | variableArgument ;
+// the command is evaluated and replaced by its return value
+substitutionArgument: '(' NL* singleCommand NL* ')' ;
+
+
+expressionArgument: '(' '=' expression ')' ;
+
+// inside an expression operators are tokens on their own, so names
+// of variables cannot contain them ($a-1 is read as $a - 1).
+// Alternatives are listed from the highest to the lowest precedence
+expression
+   : '(' expression ')'                                     # Group
+   | '-' expression                                         # Negate
+   | expression op=('*'|'/'|'%') expression                 # MulDivMod
+   | expression op=('+'|'-') expression                     # AddSub
+   | expression op=('=='|'!='|'<'|'<='|'>'|'>=') expression # Compare
+   | 'not' expression                                       # Not
+   | expression 'and' expression                            # And
+   | expression 'or' expression                             # Or
+   | (NUMBER | STRING | RAW_STRING | '$' IDENTIFIER | 'true' | 'false') # Operand
+   ;
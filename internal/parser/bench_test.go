@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andrebq/gshell/internal/parser/legacy"
+)
+
+// benchmarkScript only uses constructs known by the legacy parser
+var benchmarkScript = func() string {
+	var buf strings.Builder
+	buf.WriteString("{\n")
+	for i := 0; i < 5; i++ {
+		buf.WriteString(`	# module state
+	let $moduleVar 10
+	func print-a-and-b [$a $b] {
+		let $localVar 20
+		println "a=$a b=${b}" $moduleVar $localVar #| debug |#
+	}
+	switch {
+		case { true; } { println true; }
+		else { println [ 1 2.5 abc $var [ nested ] ]; }
+	}
+	loop i from 1 to 5 { print-a-and-b $i [10 20] }
+`)
+	}
+	buf.WriteString("}")
+	return buf.String()
+}()
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkScript)))
+	for i := 0; i < b.N; i++ {
+		if _, err := Parse(benchmarkScript); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseLegacy(b *testing.B) {
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkScript)))
+	for i := 0; i < b.N; i++ {
+		if _, err := legacy.Parse(benchmarkScript); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkParseLine measures the cost of parsing a single
+// line, like a REPL does on every key stroke
+func BenchmarkParseLine(b *testing.B) {
+	const line = `{ println "hello $name" [ 1 2 3 ] }`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParsePartial(line); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -2,18 +2,49 @@ package parser
 
 import (
 	"fmt"
-	"strconv"
+	"sort"
+	"strings"
 
-	"github.com/antlr/antlr4/runtime/Go/antlr"
+	"github.com/andrebq/gshell/ast"
 )
 
 type (
-	errorsListener struct {
-		antlr.ErrorListener
-		err error
+	// SyntaxError describes one problem found while parsing
+	SyntaxError struct {
+		// Span of the offending token
+		Span ast.Span
+		// Offending is the text of the token which caused the error
+		Offending string
+		// Expected lists the tokens that would be valid
+		// at this point, it might be empty
+		Expected []string
+		Msg      string
+
+		// atEOF is set when the error was caused by the
+		// end of the input
+		atEOF bool
 	}
+
+	// Errors is returned by Parse with every syntax error
+	// found in the code, in the order they appear in the code
+	Errors []*SyntaxError
 )
 
-func (el *errorsListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
-	el.err = fmt.Errorf("line " + strconv.Itoa(line) + ":" + strconv.Itoa(column) + " " + msg)
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Span, e.Msg)
+}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		a, b := e[i].Span.Start, e[j].Span.Start
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
 }
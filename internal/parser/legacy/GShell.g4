@@ -0,0 +1,99 @@
+grammar GShell;
+
+// Tokens
+fragment LETTER: [\p{Ll}|\p{Lu}];
+fragment DIGIT: [\p{Nd}];
+fragment PUNCTUATION_HEAD: [!?.\-+*&^%@~];
+fragment PUCTUATION_TAIL: PUNCTUATION_HEAD | [$#];
+fragment INT: '-' DIGIT+ | DIGIT+;
+fragment FLOAT: INT '.' DIGIT+;
+fragment IDENTIFER_START: LETTER|PUNCTUATION_HEAD;
+fragment IDENTIFIER_TAIL: (DIGIT|LETTER|PUCTUATION_TAIL);
+fragment BRACED: '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* '}';
+fragment HEX: [0-9a-fA-F];
+fragment VALID_ESCAPE: '\\' ([ntr"$\\] | 'u' HEX HEX HEX HEX);
+// the prefix of an escape sequence cut by the end of the input
+fragment OPEN_ESCAPE: '\\' ('u' HEX? HEX? HEX?)?;
+fragment OPEN_STRING: '"' (VALID_ESCAPE | '$' BRACED | ~["\\])* (OPEN_ESCAPE | '$' OPEN_BRACED)?;
+fragment OPEN_RAW_STRING: '\'' ~[']*;
+fragment OPEN_BRACED: '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* (OPEN_BRACED | OPEN_STRING | OPEN_RAW_STRING)?;
+
+IDENTIFIER: IDENTIFER_START IDENTIFIER_TAIL*;
+NUMBER: INT | FLOAT;
+// $name, ${name} and ${ commands... } inside a STRING are
+// interpolated, the parser splits the string into an ast.Template.
+// Inside a STRING the name of a variable ends at '$' ("$a$b" is $a then $b).
+// Escape sequences are checked by the parser, so an invalid
+// one is reported once instead of breaking the whole string
+STRING: '"' ('\\' . | '$' BRACED | ~["\\])* '"';
+RAW_STRING: '\'' ~[']* '\'';
+// a string cut by the end of the input is reported by the parser,
+// more input could still close it. One with an invalid escape
+// sequence is a lexer error as no input can fix it
+UNTERMINATED_STRING: (OPEN_STRING | OPEN_RAW_STRING) EOF;
+
+TERMINATOR: [;];
+NL: [\n];
+
+WS: [ \r\t]+ -> skip;
+
+// comments are attached to the closest command or script
+// by the parser, so the formatter can write them back
+BLOCK_COMMENT: '#|' .*? '|#' -> channel(HIDDEN);
+// reported as an error by the parser
+UNTERMINATED_COMMENT: '#|' (~'|' | '|'+ ~[|#])* '|'* EOF -> channel(HIDDEN);
+LINE_COMMENT: '#' (~[|\n] ~[\n]*)? -> channel(HIDDEN);
+
+// Rules
+start
+   : script EOF;
+
+terminator
+   : TERMINATOR
+   | NL ;
+
+commandListItem
+   : singleCommand (terminator NL*)? ;
+
+openBlock
+   : '{';
+
+closeBlock
+   : '}';
+
+commandBlock
+   : openBlock NL* commandListItem* closeBlock ;
+
+script
+   : NL* commandBlock NL* EOF;
+
+// entry rule for files, where top-level commands
+// are not wrapped in a block
+program
+   : NL* commandListItem* EOF;
+
+singleCommand
+   : commandName argument* ;
+
+commandName : IDENTIFIER ;
+
+argument
+   : namedArgument
+   | numericArgument
+   | textArgument
+   | variableArgument
+   | scriptArgument
+   | listArgument ;
+
+namedArgument : IDENTIFIER ;
+numericArgument : NUMBER ;
+textArgument : STRING | RAW_STRING | UNTERMINATED_STRING ;
+variableArgument : '$' IDENTIFIER ;
+scriptArgument: commandBlock ;
+listArgument: '[' (argument | NL)* ']' ;
+
+// expression
+//    : expression op=('*'|'/') expression # MulDiv
+//    | expression op=('+'|'-') expression # AddSub
+//    | NUMBER                             # Number
+//    ;
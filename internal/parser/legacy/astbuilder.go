@@ -0,0 +1,454 @@
+package legacy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrebq/gshell/ast"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+type (
+	stack []interface{}
+
+	astBuilder struct {
+		*BaseGShellListener
+		ast *ast.Ast
+
+		errs Errors
+		// commands with syntax errors are left out of the tree
+		broken map[*SingleCommandContext]bool
+
+		stack stack
+
+		// comments are kept in the hidden channel, tokens
+		// before next have already been attached to a node
+		tokens *antlr.CommonTokenStream
+		next   int
+
+		// spans refer to source and start at origin
+		source string
+		origin ast.Position
+	}
+
+	// node is an argument kept in the stack along
+	// with the region of the code where it was found
+	node struct {
+		value ast.Argument
+		span  ast.Span
+	}
+)
+
+func newAstBuilder(tokens *antlr.CommonTokenStream, broken map[*SingleCommandContext]bool, source string, origin ast.Position) *astBuilder {
+	return &astBuilder{
+		BaseGShellListener: &BaseGShellListener{},
+		ast:                ast.New(),
+		broken:             broken,
+		tokens:             tokens,
+		source:             source,
+		origin:             origin,
+	}
+}
+
+// Parse takes the code of a script block and returns its AST.
+//
+// Syntax errors are returned as Errors along with the tree of the
+// code around them, commands with errors are left out of the tree
+func Parse(code string) (*ast.Ast, error) {
+	return ParseSource("", code)
+}
+
+// ParseSource works like Parse but the spans of the nodes
+// refer to the given source (usually a file name)
+func ParseSource(source, code string) (*ast.Ast, error) {
+	return parse(source, code, ast.Position{Line: 1, Column: 1}, false)
+}
+
+// ParseProgram parses code written as a file, where the top-level
+// commands are not wrapped in a block. It produces the same kind of
+// tree as Parse, marked with Ast.IsProgram so it is formatted
+// without the braces
+func ParseProgram(source, code string) (*ast.Ast, error) {
+	return parse(source, code, ast.Position{Line: 1, Column: 1}, true)
+}
+
+// parse reads code which starts at origin, blocks interpolated
+// in strings are parsed on their own from the middle of a line.
+// When program is true the code is read with the program rule
+func parse(source, code string, origin ast.Position, program bool) (*ast.Ast, error) {
+	lexer := NewGShellLexer(antlr.NewInputStream(code))
+	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	parser := NewGShellParser(stream)
+	errorsFound := &errorsListener{
+		ErrorListener: antlr.NewDefaultErrorListener(),
+		source:        source,
+		origin:        origin,
+	}
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(errorsFound)
+	parser.RemoveErrorListeners()
+	parser.AddErrorListener(errorsFound)
+	strategy := newErrorStrategy()
+	parser.SetErrorHandler(strategy)
+	astBuilder := newAstBuilder(stream, strategy.broken, source, origin)
+	if program {
+		astBuilder.ast.SetProgram(true)
+		astBuilder.walk(parser.Program())
+	} else {
+		astBuilder.walk(parser.Start())
+	}
+
+	errs := append(errorsFound.errs, astBuilder.errs...)
+	if len(errs) > 0 {
+		errs.sort()
+		return astBuilder.ast, errs
+	}
+	return astBuilder.ast, nil
+}
+
+// walk works like antlr.ParseTreeWalkerDefault.Walk
+// but skips the commands with syntax errors
+func (ab *astBuilder) walk(tree antlr.Tree) {
+	ctx, ok := tree.(antlr.ParserRuleContext)
+	if !ok {
+		return
+	}
+	if cmd, ok := ctx.(*SingleCommandContext); ok && ab.broken[cmd] {
+		return
+	}
+	ctx.EnterRule(ab)
+	for _, child := range ctx.GetChildren() {
+		ab.walk(child)
+	}
+	ctx.ExitRule(ab)
+}
+
+func (ab *astBuilder) ExitStart(c *StartContext) {
+	ab.ast.SetRoot(ab.stack.pop().(*ast.Script))
+	eof := ab.tokens.Get(ab.tokens.Size() - 1)
+	ab.ast.AddTrailingComment(ab.takeComments(eof)...)
+}
+
+func (ab *astBuilder) EnterScript(c *ScriptContext) {
+	ab.ast.AddLeadingComment(ab.takeComments(c.GetStart())...)
+	ab.stack.push(ast.NewScript())
+}
+
+func (ab *astBuilder) ExitScript(c *ScriptContext) {
+	steps := new(stack)
+	for !isScript(ab.stack) {
+		steps.push(ab.stack.pop().(*ast.Cmd))
+	}
+
+	sc := ab.stack.pop().(*ast.Script)
+	for !steps.empty() {
+		sc.AddCommand(steps.pop().(*ast.Cmd))
+	}
+	sc.AddComment(ab.takeComments(c.CommandBlock().GetStop())...)
+	sc.SetSpan(ab.ctxSpan(c.CommandBlock()))
+	ab.stack.push(sc)
+}
+
+func (ab *astBuilder) EnterProgram(c *ProgramContext) {
+	ab.stack.push(ast.NewScript())
+}
+
+func (ab *astBuilder) ExitProgram(c *ProgramContext) {
+	steps := new(stack)
+	for !isScript(ab.stack) {
+		steps.push(ab.stack.pop().(*ast.Cmd))
+	}
+	sc := ab.stack.pop().(*ast.Script)
+	for !steps.empty() {
+		sc.AddCommand(steps.pop().(*ast.Cmd))
+	}
+	eof := ab.tokens.Get(ab.tokens.Size() - 1)
+	sc.AddComment(ab.takeComments(eof)...)
+	ab.ast.SetRoot(sc.SetSpan(ab.ctxSpan(c)))
+}
+
+func (ab *astBuilder) EnterScriptArgument(c *ScriptArgumentContext) {
+	ab.stack.push(ast.NewScript())
+}
+
+func (ab *astBuilder) ExitScriptArgument(c *ScriptArgumentContext) {
+	steps := new(stack)
+	for !isScript(ab.stack) {
+		steps.push(ab.stack.pop().(*ast.Cmd))
+	}
+	sc := ab.stack.pop().(*ast.Script)
+	for !steps.empty() {
+		sc.AddCommand(steps.pop().(*ast.Cmd))
+	}
+	sc.AddComment(ab.takeComments(c.GetStop())...)
+	span := ab.ctxSpan(c)
+	ab.stack.push(node{sc.SetSpan(span), span})
+}
+
+func (ab *astBuilder) EnterSingleCommand(c *SingleCommandContext) {
+	cmd := &ast.Cmd{}
+	cmd.AddLeadingComment(ab.takeComments(c.GetStart())...)
+	ab.stack.push(cmd)
+}
+
+func (ab *astBuilder) ExitSingleCommand(c *SingleCommandContext) {
+	argst := new(stack)
+	for !isCmd(ab.stack) {
+		argst.push(ab.stack.pop())
+	}
+
+	cmd := ab.stack.pop().(*ast.Cmd)
+
+	for !argst.empty() {
+		arg := argst.pop().(node)
+		if cmd.Command() == (ast.Symbol{}) {
+			cmd.SetCommand(arg.value.(ast.Symbol))
+			continue
+		}
+		cmd.AddArgumentAt(arg.value, arg.span)
+	}
+	cmd.SetSpan(ab.ctxSpan(c))
+
+	// comments up to the end of the line belong to the command
+	end := ab.nextToken(c.GetStop())
+	if end.GetTokenType() == GShellParserTERMINATOR {
+		end = ab.nextToken(end)
+	}
+	cmd.AddTrailingComment(ab.takeComments(end)...)
+
+	ab.stack.push(cmd)
+}
+
+func (ab *astBuilder) ExitCommandName(c *CommandNameContext) {
+	s, err := ast.NewSymbol(c.GetText())
+	if err != nil {
+		ab.fail(c.GetStart(), fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", c.GetText(), err))
+		s, _ = ast.NewSymbol("error-invalid-symbol")
+	}
+	ab.stack.push(node{s, ab.ctxSpan(c)})
+}
+
+func (ab *astBuilder) ExitNumericArgument(c *NumericArgumentContext) {
+	number, err := strconv.ParseFloat(c.GetText(), 63)
+	if err != nil {
+		ab.stack.push(node{ast.NewText(c.GetText()), ab.ctxSpan(c)})
+	} else {
+		ab.stack.push(node{ast.NewNumber(number), ab.ctxSpan(c)})
+	}
+}
+
+func (ab *astBuilder) ExitTextArgument(c *TextArgumentContext) {
+	text, span := c.GetText(), ab.ctxSpan(c)
+	switch {
+	case c.RAW_STRING() != nil:
+		ab.stack.push(node{ast.NewText(text[1 : len(text)-1]), span})
+		return
+	case c.UNTERMINATED_STRING() != nil:
+		ab.fail(c.GetStart(), "unterminated string").atEOF = true
+		ab.stack.push(node{ast.NewText(text[1:]), span})
+		return
+	}
+	parts, err := unquote(text)
+	if err != nil {
+		ab.fail(c.GetStart(), err.Error())
+	}
+	switch {
+	case len(parts) == 0:
+		ab.stack.push(node{ast.NewText(""), span})
+	case len(parts) == 1 && parts[0].kind == partText:
+		ab.stack.push(node{ast.NewText(parts[0].text), span})
+	default:
+		ab.stack.push(node{ab.template(c.GetStart(), parts).SetSpan(span), span})
+	}
+}
+
+// template converts the parts of an interpolated string, blocks
+// are parsed on their own as they are kept inside the STRING token
+func (ab *astBuilder) template(tok antlr.Token, parts []stringPart) *ast.Template {
+	tmpl := ast.NewTemplate()
+	for _, part := range parts {
+		switch part.kind {
+		case partText:
+			tmpl.AddPart(ast.NewText(part.text))
+		case partVar:
+			v, err := ast.NewVarString(part.text)
+			if err != nil {
+				ab.fail(tok, fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", part.text, err))
+			}
+			tmpl.AddPart(v.WithSpan(ast.Span{
+				Source: ab.source,
+				Start:  offsetIn(ab.origin, tok, part.start),
+				End:    offsetIn(ab.origin, tok, part.end),
+			}))
+		case partBlock:
+			// the block starts after the '$'
+			block, err := parse(ab.source, part.text, offsetIn(ab.origin, tok, part.start+1), false)
+			if errs, ok := err.(Errors); ok {
+				for _, e := range errs {
+					// the string was closed, so more input cannot fix the block
+					e.atEOF = false
+					ab.errs = append(ab.errs, e)
+				}
+			}
+			tmpl.AddPart(block.Root())
+		}
+	}
+	return tmpl
+}
+
+func (ab *astBuilder) ExitNamedArgument(c *NamedArgumentContext) {
+	s, err := ast.NewSymbol(c.GetText())
+	if err != nil {
+		ab.fail(c.GetStart(), fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", c.GetText(), err))
+		s, _ = ast.NewSymbol("error-invalid-symbol")
+	}
+	ab.stack.push(node{s, ab.ctxSpan(c)})
+}
+
+func (ab *astBuilder) ExitVariableArgument(c *VariableArgumentContext) {
+	v, err := ast.NewVarString(c.GetText()[1:])
+	if err != nil {
+		ab.fail(c.GetStart(), fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", c.GetText(), err))
+		v, _ = ast.NewVarString("error-invalid-variable")
+	}
+	span := ab.ctxSpan(c)
+	ab.stack.push(node{v.WithSpan(span), span})
+}
+
+type listStartMarker struct{}
+
+func (ab *astBuilder) EnterListArgument(c *ListArgumentContext) {
+	ab.stack.push(listStartMarker{})
+}
+
+func (ab *astBuilder) ExitListArgument(c *ListArgumentContext) {
+	var items []ast.Argument
+	var spans []ast.Span
+	for !ab.stack.empty() {
+		v := ab.stack.pop()
+		if _, isListStart := v.(listStartMarker); isListStart {
+			break
+		}
+		items = append(items, v.(node).value)
+		spans = append(spans, v.(node).span)
+	}
+	for i := 0; i < len(items)/2; i++ {
+		j := len(items) - i - 1
+		items[i], items[j] = items[j], items[i]
+		spans[i], spans[j] = spans[j], spans[i]
+	}
+	span := ab.ctxSpan(c)
+	lst := ast.NilList().Append(items...).WithSpan(span).WithItemSpans(spans)
+	ab.stack.push(node{lst, span})
+}
+
+// ctxSpan returns the region of the code matched by a rule
+func (ab *astBuilder) ctxSpan(c antlr.ParserRuleContext) ast.Span {
+	start := offsetIn(ab.origin, c.GetStart(), 0)
+	span := ast.Span{Source: ab.source, Start: start, End: start}
+	// rules with errors might end before they start
+	stop := c.GetStop()
+	switch {
+	case stop == nil || stop.GetTokenIndex() < c.GetStart().GetTokenIndex():
+	case stop.GetTokenType() == antlr.TokenEOF:
+		span.End = offsetIn(ab.origin, stop, 0)
+	default:
+		span.End = offsetIn(ab.origin, stop, len(stop.GetText()))
+	}
+	return span
+}
+
+// fail records an error found at tok
+func (ab *astBuilder) fail(tok antlr.Token, msg string) *SyntaxError {
+	pos := offsetIn(ab.origin, tok, 0)
+	err := &SyntaxError{
+		Span:      ast.Span{Source: ab.source, Start: pos, End: offsetIn(ab.origin, tok, len(tok.GetText()))},
+		Offending: tok.GetText(),
+		Msg:       msg,
+	}
+	ab.errs = append(ab.errs, err)
+	return err
+}
+
+// offsetIn returns the position of the byte at offset inside
+// the text of tok, for code which starts at origin
+func offsetIn(origin ast.Position, tok antlr.Token, offset int) ast.Position {
+	line, column := tok.GetLine(), tok.GetColumn()
+	text := tok.GetText()[:offset]
+	if nl := strings.LastIndexByte(text, '\n'); nl >= 0 {
+		line += strings.Count(text, "\n")
+		column = 0
+		text = text[nl+1:]
+	}
+	return shift(origin, line, column+utf8.RuneCountInString(text))
+}
+
+// shift returns the position of line and column (from 0) as reported
+// by ANTLR for code which starts at origin, columns count runes from 1
+func shift(origin ast.Position, line, column int) ast.Position {
+	if line == 1 {
+		column += origin.Column - 1
+	}
+	return ast.Position{Line: line + origin.Line - 1, Column: column + 1}
+}
+
+// takeComments returns the comments found before the given
+// token which were not attached to another node
+func (ab *astBuilder) takeComments(upto antlr.Token) []ast.Comment {
+	var comments []ast.Comment
+	if upto == nil {
+		// the code has no tokens at all
+		return nil
+	}
+	for ; ab.next < upto.GetTokenIndex(); ab.next++ {
+		tok := ab.tokens.Get(ab.next)
+		if tok.GetTokenType() == GShellLexerUNTERMINATED_COMMENT {
+			ab.fail(tok, "unterminated block comment").atEOF = true
+		}
+		if tok.GetChannel() == antlr.TokenHiddenChannel {
+			comments = append(comments, ast.NewComment(tok.GetText()))
+		}
+	}
+	return comments
+}
+
+// nextToken returns the token after tok, ignoring comments
+func (ab *astBuilder) nextToken(tok antlr.Token) antlr.Token {
+	for i := tok.GetTokenIndex() + 1; ; i++ {
+		next := ab.tokens.Get(i)
+		if next.GetChannel() == antlr.TokenDefaultChannel || next.GetTokenType() == antlr.TokenEOF {
+			return next
+		}
+	}
+}
+
+func (s *stack) push(v interface{}) {
+	*s = append(*s, v)
+}
+
+func (s *stack) pop() interface{} {
+	v := (*s)[len(*s)-1]
+	// ensure gc cannot reach the old value
+	(*s)[len(*s)-1] = nil
+	*s = (*s)[:len(*s)-1]
+	return v
+}
+
+func (s *stack) peek() interface{} {
+	return (*s)[len(*s)-1]
+}
+
+func (s *stack) empty() bool { return len(*s) == 0 }
+func (s *stack) depth() int  { return len(*s) }
+
+func isCmd(st stack) bool {
+	_, ok := st.peek().(*ast.Cmd)
+	return ok
+}
+
+func isScript(st stack) bool {
+	_, ok := st.peek().(*ast.Script)
+	return ok
+}
@@ -0,0 +1,7 @@
+// Package legacy is the ANTLR parser which was replaced by the
+// hand-written one in package parser.
+//
+// It is frozen at the grammar in GShell.g4 and only kept to check
+// that both parsers produce the same trees and to compare their
+// performance, new syntax is never added here
+package legacy
@@ -0,0 +1,104 @@
+package legacy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+type (
+	// SyntaxError describes one problem found while parsing
+	SyntaxError struct {
+		// Span of the offending token
+		Span ast.Span
+		// Offending is the text of the token which caused the error
+		Offending string
+		// Expected lists the tokens that would be valid
+		// at this point, it might be empty
+		Expected []string
+		Msg      string
+
+		// atEOF is set when the error was caused by the
+		// end of the input
+		atEOF bool
+	}
+
+	// Errors is returned by Parse with every syntax error
+	// found in the code, in the order they appear in the code
+	Errors []*SyntaxError
+
+	errorsListener struct {
+		antlr.ErrorListener
+		errs Errors
+
+		source string
+		origin ast.Position
+	}
+)
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Span, e.Msg)
+}
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e Errors) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		a, b := e[i].Span.Start, e[j].Span.Start
+		return a.Line < b.Line || (a.Line == b.Line && a.Column < b.Column)
+	})
+}
+
+func (el *errorsListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol interface{}, line, column int, msg string, e antlr.RecognitionException) {
+	pos := shift(el.origin, line, column)
+	err := &SyntaxError{
+		Span: ast.Span{Source: el.source, Start: pos, End: pos},
+		Msg:  msg,
+	}
+	if tok, ok := offendingSymbol.(antlr.Token); ok {
+		err.Offending = tok.GetText()
+		if tok.GetTokenType() == antlr.TokenEOF {
+			err.Offending = "<EOF>"
+			err.atEOF = true
+		} else {
+			err.Span.End = offsetIn(el.origin, tok, len(tok.GetText()))
+		}
+	}
+	if p, ok := recognizer.(antlr.Parser); ok {
+		err.Expected = expectedTokens(p)
+	}
+	el.errs = append(el.errs, err)
+}
+
+// expectedTokens returns the names of the tokens which
+// the parser would accept at its current state
+func expectedTokens(p antlr.Parser) []string {
+	var names []string
+	for t := antlr.TokenEOF; t < len(p.GetSymbolicNames()); t++ {
+		if t != antlr.TokenInvalidType && p.IsExpectedToken(t) {
+			names = append(names, tokenName(p, t))
+		}
+	}
+	return names
+}
+
+func tokenName(p antlr.Parser, t int) string {
+	switch {
+	case t == antlr.TokenEOF:
+		return "<EOF>"
+	case t < len(p.GetLiteralNames()) && p.GetLiteralNames()[t] != "":
+		return p.GetLiteralNames()[t]
+	case t < len(p.GetSymbolicNames()):
+		return p.GetSymbolicNames()[t]
+	}
+	return fmt.Sprintf("<%v>", t)
+}
@@ -1,6 +1,6 @@
 // Code generated from GShell.g4 by ANTLR 4.8. DO NOT EDIT.
 
-package parser // GShell
+package legacy // GShell
 
 import "github.com/antlr/antlr4/runtime/Go/antlr"
 
@@ -57,41 +57,35 @@ func (s *BaseGShellListener) EnterCommandBlock(ctx *CommandBlockContext) {}
 // ExitCommandBlock is called when production commandBlock is exited.
 func (s *BaseGShellListener) ExitCommandBlock(ctx *CommandBlockContext) {}
 
-// EnterCommandBlockTail is called when production commandBlockTail is entered.
-func (s *BaseGShellListener) EnterCommandBlockTail(ctx *CommandBlockTailContext) {}
-
-// ExitCommandBlockTail is called when production commandBlockTail is exited.
-func (s *BaseGShellListener) ExitCommandBlockTail(ctx *CommandBlockTailContext) {}
-
 // EnterScript is called when production script is entered.
 func (s *BaseGShellListener) EnterScript(ctx *ScriptContext) {}
 
 // ExitScript is called when production script is exited.
 func (s *BaseGShellListener) ExitScript(ctx *ScriptContext) {}
 
+// EnterProgram is called when production program is entered.
+func (s *BaseGShellListener) EnterProgram(ctx *ProgramContext) {}
+
+// ExitProgram is called when production program is exited.
+func (s *BaseGShellListener) ExitProgram(ctx *ProgramContext) {}
+
 // EnterSingleCommand is called when production singleCommand is entered.
 func (s *BaseGShellListener) EnterSingleCommand(ctx *SingleCommandContext) {}
 
 // ExitSingleCommand is called when production singleCommand is exited.
 func (s *BaseGShellListener) ExitSingleCommand(ctx *SingleCommandContext) {}
 
-// EnterCommandLine is called when production commandLine is entered.
-func (s *BaseGShellListener) EnterCommandLine(ctx *CommandLineContext) {}
-
-// ExitCommandLine is called when production commandLine is exited.
-func (s *BaseGShellListener) ExitCommandLine(ctx *CommandLineContext) {}
-
 // EnterCommandName is called when production commandName is entered.
 func (s *BaseGShellListener) EnterCommandName(ctx *CommandNameContext) {}
 
 // ExitCommandName is called when production commandName is exited.
 func (s *BaseGShellListener) ExitCommandName(ctx *CommandNameContext) {}
 
-// EnterArguments is called when production arguments is entered.
-func (s *BaseGShellListener) EnterArguments(ctx *ArgumentsContext) {}
+// EnterArgument is called when production argument is entered.
+func (s *BaseGShellListener) EnterArgument(ctx *ArgumentContext) {}
 
-// ExitArguments is called when production arguments is exited.
-func (s *BaseGShellListener) ExitArguments(ctx *ArgumentsContext) {}
+// ExitArgument is called when production argument is exited.
+func (s *BaseGShellListener) ExitArgument(ctx *ArgumentContext) {}
 
 // EnterNamedArgument is called when production namedArgument is entered.
 func (s *BaseGShellListener) EnterNamedArgument(ctx *NamedArgumentContext) {}
@@ -105,6 +99,12 @@ func (s *BaseGShellListener) EnterNumericArgument(ctx *NumericArgumentContext) {
 // ExitNumericArgument is called when production numericArgument is exited.
 func (s *BaseGShellListener) ExitNumericArgument(ctx *NumericArgumentContext) {}
 
+// EnterTextArgument is called when production textArgument is entered.
+func (s *BaseGShellListener) EnterTextArgument(ctx *TextArgumentContext) {}
+
+// ExitTextArgument is called when production textArgument is exited.
+func (s *BaseGShellListener) ExitTextArgument(ctx *TextArgumentContext) {}
+
 // EnterVariableArgument is called when production variableArgument is entered.
 func (s *BaseGShellListener) EnterVariableArgument(ctx *VariableArgumentContext) {}
 
@@ -1,6 +1,6 @@
 // Code generated from GShell.g4 by ANTLR 4.8. DO NOT EDIT.
 
-package parser // GShell
+package legacy // GShell
 
 import "github.com/antlr/antlr4/runtime/Go/antlr"
 
@@ -26,23 +26,20 @@ type GShellListener interface {
 	// EnterCommandBlock is called when entering the commandBlock production.
 	EnterCommandBlock(c *CommandBlockContext)
 
-	// EnterCommandBlockTail is called when entering the commandBlockTail production.
-	EnterCommandBlockTail(c *CommandBlockTailContext)
-
 	// EnterScript is called when entering the script production.
 	EnterScript(c *ScriptContext)
 
+	// EnterProgram is called when entering the program production.
+	EnterProgram(c *ProgramContext)
+
 	// EnterSingleCommand is called when entering the singleCommand production.
 	EnterSingleCommand(c *SingleCommandContext)
 
-	// EnterCommandLine is called when entering the commandLine production.
-	EnterCommandLine(c *CommandLineContext)
-
 	// EnterCommandName is called when entering the commandName production.
 	EnterCommandName(c *CommandNameContext)
 
-	// EnterArguments is called when entering the arguments production.
-	EnterArguments(c *ArgumentsContext)
+	// EnterArgument is called when entering the argument production.
+	EnterArgument(c *ArgumentContext)
 
 	// EnterNamedArgument is called when entering the namedArgument production.
 	EnterNamedArgument(c *NamedArgumentContext)
@@ -50,6 +47,9 @@ type GShellListener interface {
 	// EnterNumericArgument is called when entering the numericArgument production.
 	EnterNumericArgument(c *NumericArgumentContext)
 
+	// EnterTextArgument is called when entering the textArgument production.
+	EnterTextArgument(c *TextArgumentContext)
+
 	// EnterVariableArgument is called when entering the variableArgument production.
 	EnterVariableArgument(c *VariableArgumentContext)
 
@@ -77,23 +77,20 @@ type GShellListener interface {
 	// ExitCommandBlock is called when exiting the commandBlock production.
 	ExitCommandBlock(c *CommandBlockContext)
 
-	// ExitCommandBlockTail is called when exiting the commandBlockTail production.
-	ExitCommandBlockTail(c *CommandBlockTailContext)
-
 	// ExitScript is called when exiting the script production.
 	ExitScript(c *ScriptContext)
 
+	// ExitProgram is called when exiting the program production.
+	ExitProgram(c *ProgramContext)
+
 	// ExitSingleCommand is called when exiting the singleCommand production.
 	ExitSingleCommand(c *SingleCommandContext)
 
-	// ExitCommandLine is called when exiting the commandLine production.
-	ExitCommandLine(c *CommandLineContext)
-
 	// ExitCommandName is called when exiting the commandName production.
 	ExitCommandName(c *CommandNameContext)
 
-	// ExitArguments is called when exiting the arguments production.
-	ExitArguments(c *ArgumentsContext)
+	// ExitArgument is called when exiting the argument production.
+	ExitArgument(c *ArgumentContext)
 
 	// ExitNamedArgument is called when exiting the namedArgument production.
 	ExitNamedArgument(c *NamedArgumentContext)
@@ -101,6 +98,9 @@ type GShellListener interface {
 	// ExitNumericArgument is called when exiting the numericArgument production.
 	ExitNumericArgument(c *NumericArgumentContext)
 
+	// ExitTextArgument is called when exiting the textArgument production.
+	ExitTextArgument(c *TextArgumentContext)
+
 	// ExitVariableArgument is called when exiting the variableArgument production.
 	ExitVariableArgument(c *VariableArgumentContext)
 
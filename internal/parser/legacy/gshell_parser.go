@@ -1,6 +1,6 @@
 // Code generated from GShell.g4 by ANTLR 4.8. DO NOT EDIT.
 
-package parser // GShell
+package legacy // GShell
 
 import (
 	"fmt"
@@ -16,72 +16,62 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 12, 160,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 18, 138,
 	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
 	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13,
 	9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4, 18, 9,
-	18, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 4, 7, 4, 43, 10, 4, 12, 4, 14, 4,
-	46, 11, 4, 3, 4, 3, 4, 3, 4, 7, 4, 51, 10, 4, 12, 4, 14, 4, 54, 11, 4,
-	3, 5, 3, 5, 3, 6, 3, 6, 3, 7, 3, 7, 3, 7, 3, 8, 7, 8, 64, 10, 8, 12, 8,
-	14, 8, 67, 11, 8, 3, 8, 3, 8, 3, 8, 7, 8, 72, 10, 8, 12, 8, 14, 8, 75,
-	11, 8, 3, 8, 3, 8, 3, 8, 7, 8, 80, 10, 8, 12, 8, 14, 8, 83, 11, 8, 3, 8,
-	3, 8, 7, 8, 87, 10, 8, 12, 8, 14, 8, 90, 11, 8, 3, 8, 3, 8, 3, 8, 5, 8,
-	95, 10, 8, 3, 9, 3, 9, 3, 9, 3, 10, 3, 10, 3, 11, 3, 11, 3, 11, 3, 11,
-	5, 11, 106, 10, 11, 3, 12, 3, 12, 3, 13, 3, 13, 7, 13, 112, 10, 13, 12,
-	13, 14, 13, 115, 11, 13, 3, 13, 3, 13, 7, 13, 119, 10, 13, 12, 13, 14,
-	13, 122, 11, 13, 3, 13, 3, 13, 7, 13, 126, 10, 13, 12, 13, 14, 13, 129,
-	11, 13, 3, 13, 3, 13, 7, 13, 133, 10, 13, 12, 13, 14, 13, 136, 11, 13,
-	3, 13, 3, 13, 7, 13, 140, 10, 13, 12, 13, 14, 13, 143, 11, 13, 5, 13, 145,
-	10, 13, 3, 14, 3, 14, 3, 15, 3, 15, 3, 16, 3, 16, 3, 16, 3, 17, 3, 17,
-	3, 18, 3, 18, 3, 18, 3, 18, 3, 18, 2, 2, 19, 2, 4, 6, 8, 10, 12, 14, 16,
-	18, 20, 22, 24, 26, 28, 30, 32, 34, 2, 3, 3, 2, 10, 11, 2, 160, 2, 36,
-	3, 2, 2, 2, 4, 39, 3, 2, 2, 2, 6, 44, 3, 2, 2, 2, 8, 55, 3, 2, 2, 2, 10,
-	57, 3, 2, 2, 2, 12, 59, 3, 2, 2, 2, 14, 94, 3, 2, 2, 2, 16, 96, 3, 2, 2,
-	2, 18, 99, 3, 2, 2, 2, 20, 105, 3, 2, 2, 2, 22, 107, 3, 2, 2, 2, 24, 144,
-	3, 2, 2, 2, 26, 146, 3, 2, 2, 2, 28, 148, 3, 2, 2, 2, 30, 150, 3, 2, 2,
-	2, 32, 153, 3, 2, 2, 2, 34, 155, 3, 2, 2, 2, 36, 37, 5, 16, 9, 2, 37, 38,
-	7, 2, 2, 3, 38, 3, 3, 2, 2, 2, 39, 40, 9, 2, 2, 2, 40, 5, 3, 2, 2, 2, 41,
-	43, 7, 11, 2, 2, 42, 41, 3, 2, 2, 2, 43, 46, 3, 2, 2, 2, 44, 42, 3, 2,
-	2, 2, 44, 45, 3, 2, 2, 2, 45, 47, 3, 2, 2, 2, 46, 44, 3, 2, 2, 2, 47, 48,
-	5, 18, 10, 2, 48, 52, 5, 4, 3, 2, 49, 51, 7, 11, 2, 2, 50, 49, 3, 2, 2,
-	2, 51, 54, 3, 2, 2, 2, 52, 50, 3, 2, 2, 2, 52, 53, 3, 2, 2, 2, 53, 7, 3,
-	2, 2, 2, 54, 52, 3, 2, 2, 2, 55, 56, 7, 3, 2, 2, 56, 9, 3, 2, 2, 2, 57,
-	58, 7, 4, 2, 2, 58, 11, 3, 2, 2, 2, 59, 60, 5, 8, 5, 2, 60, 61, 5, 14,
-	8, 2, 61, 13, 3, 2, 2, 2, 62, 64, 7, 11, 2, 2, 63, 62, 3, 2, 2, 2, 64,
-	67, 3, 2, 2, 2, 65, 63, 3, 2, 2, 2, 65, 66, 3, 2, 2, 2, 66, 68, 3, 2, 2,
-	2, 67, 65, 3, 2, 2, 2, 68, 69, 5, 18, 10, 2, 69, 73, 5, 4, 3, 2, 70, 72,
-	7, 11, 2, 2, 71, 70, 3, 2, 2, 2, 72, 75, 3, 2, 2, 2, 73, 71, 3, 2, 2, 2,
-	73, 74, 3, 2, 2, 2, 74, 76, 3, 2, 2, 2, 75, 73, 3, 2, 2, 2, 76, 77, 5,
-	14, 8, 2, 77, 95, 3, 2, 2, 2, 78, 80, 7, 11, 2, 2, 79, 78, 3, 2, 2, 2,
-	80, 83, 3, 2, 2, 2, 81, 79, 3, 2, 2, 2, 81, 82, 3, 2, 2, 2, 82, 84, 3,
-	2, 2, 2, 83, 81, 3, 2, 2, 2, 84, 88, 5, 18, 10, 2, 85, 87, 7, 11, 2, 2,
-	86, 85, 3, 2, 2, 2, 87, 90, 3, 2, 2, 2, 88, 86, 3, 2, 2, 2, 88, 89, 3,
-	2, 2, 2, 89, 91, 3, 2, 2, 2, 90, 88, 3, 2, 2, 2, 91, 92, 5, 10, 6, 2, 92,
-	95, 3, 2, 2, 2, 93, 95, 5, 10, 6, 2, 94, 65, 3, 2, 2, 2, 94, 81, 3, 2,
-	2, 2, 94, 93, 3, 2, 2, 2, 95, 15, 3, 2, 2, 2, 96, 97, 5, 12, 7, 2, 97,
-	98, 7, 2, 2, 3, 98, 17, 3, 2, 2, 2, 99, 100, 5, 20, 11, 2, 100, 19, 3,
-	2, 2, 2, 101, 106, 5, 22, 12, 2, 102, 103, 5, 22, 12, 2, 103, 104, 5, 24,
-	13, 2, 104, 106, 3, 2, 2, 2, 105, 101, 3, 2, 2, 2, 105, 102, 3, 2, 2, 2,
-	106, 21, 3, 2, 2, 2, 107, 108, 7, 8, 2, 2, 108, 23, 3, 2, 2, 2, 109, 113,
-	5, 26, 14, 2, 110, 112, 5, 24, 13, 2, 111, 110, 3, 2, 2, 2, 112, 115, 3,
-	2, 2, 2, 113, 111, 3, 2, 2, 2, 113, 114, 3, 2, 2, 2, 114, 145, 3, 2, 2,
-	2, 115, 113, 3, 2, 2, 2, 116, 120, 5, 28, 15, 2, 117, 119, 5, 24, 13, 2,
-	118, 117, 3, 2, 2, 2, 119, 122, 3, 2, 2, 2, 120, 118, 3, 2, 2, 2, 120,
-	121, 3, 2, 2, 2, 121, 145, 3, 2, 2, 2, 122, 120, 3, 2, 2, 2, 123, 127,
-	5, 30, 16, 2, 124, 126, 5, 24, 13, 2, 125, 124, 3, 2, 2, 2, 126, 129, 3,
-	2, 2, 2, 127, 125, 3, 2, 2, 2, 127, 128, 3, 2, 2, 2, 128, 145, 3, 2, 2,
-	2, 129, 127, 3, 2, 2, 2, 130, 134, 5, 32, 17, 2, 131, 133, 5, 24, 13, 2,
-	132, 131, 3, 2, 2, 2, 133, 136, 3, 2, 2, 2, 134, 132, 3, 2, 2, 2, 134,
-	135, 3, 2, 2, 2, 135, 145, 3, 2, 2, 2, 136, 134, 3, 2, 2, 2, 137, 141,
-	5, 34, 18, 2, 138, 140, 5, 24, 13, 2, 139, 138, 3, 2, 2, 2, 140, 143, 3,
-	2, 2, 2, 141, 139, 3, 2, 2, 2, 141, 142, 3, 2, 2, 2, 142, 145, 3, 2, 2,
-	2, 143, 141, 3, 2, 2, 2, 144, 109, 3, 2, 2, 2, 144, 116, 3, 2, 2, 2, 144,
-	123, 3, 2, 2, 2, 144, 130, 3, 2, 2, 2, 144, 137, 3, 2, 2, 2, 145, 25, 3,
-	2, 2, 2, 146, 147, 7, 8, 2, 2, 147, 27, 3, 2, 2, 2, 148, 149, 7, 9, 2,
-	2, 149, 29, 3, 2, 2, 2, 150, 151, 7, 5, 2, 2, 151, 152, 7, 8, 2, 2, 152,
-	31, 3, 2, 2, 2, 153, 154, 5, 12, 7, 2, 154, 33, 3, 2, 2, 2, 155, 156, 7,
-	6, 2, 2, 156, 157, 5, 24, 13, 2, 157, 158, 7, 7, 2, 2, 158, 35, 3, 2, 2,
-	2, 16, 44, 52, 65, 73, 81, 88, 94, 105, 113, 120, 127, 134, 141, 144,
+	18, 3, 2, 3, 2, 3, 2, 3, 3, 3, 3, 3, 4, 3, 4, 3, 4, 7, 4, 45, 10, 4, 12,
+	4, 14, 4, 48, 11, 4, 5, 4, 50, 10, 4, 3, 5, 3, 5, 3, 6, 3, 6, 3, 7, 3,
+	7, 7, 7, 58, 10, 7, 12, 7, 14, 7, 61, 11, 7, 3, 7, 7, 7, 64, 10, 7, 12,
+	7, 14, 7, 67, 11, 7, 3, 7, 3, 7, 3, 8, 7, 8, 72, 10, 8, 12, 8, 14, 8, 75,
+	11, 8, 3, 8, 3, 8, 7, 8, 79, 10, 8, 12, 8, 14, 8, 82, 11, 8, 3, 8, 3, 8,
+	3, 9, 7, 9, 87, 10, 9, 12, 9, 14, 9, 90, 11, 9, 3, 9, 7, 9, 93, 10, 9,
+	12, 9, 14, 9, 96, 11, 9, 3, 9, 3, 9, 3, 10, 3, 10, 7, 10, 102, 10, 10,
+	12, 10, 14, 10, 105, 11, 10, 3, 11, 3, 11, 3, 12, 3, 12, 3, 12, 3, 12,
+	3, 12, 3, 12, 5, 12, 115, 10, 12, 3, 13, 3, 13, 3, 14, 3, 14, 3, 15, 3,
+	15, 3, 16, 3, 16, 3, 16, 3, 17, 3, 17, 3, 18, 3, 18, 3, 18, 7, 18, 131,
+	10, 18, 12, 18, 14, 18, 134, 11, 18, 3, 18, 3, 18, 3, 18, 2, 2, 19, 2,
+	4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 32, 34, 2, 4, 3, 2,
+	13, 14, 3, 2, 10, 12, 2, 136, 2, 36, 3, 2, 2, 2, 4, 39, 3, 2, 2, 2, 6,
+	41, 3, 2, 2, 2, 8, 51, 3, 2, 2, 2, 10, 53, 3, 2, 2, 2, 12, 55, 3, 2, 2,
+	2, 14, 73, 3, 2, 2, 2, 16, 88, 3, 2, 2, 2, 18, 99, 3, 2, 2, 2, 20, 106,
+	3, 2, 2, 2, 22, 114, 3, 2, 2, 2, 24, 116, 3, 2, 2, 2, 26, 118, 3, 2, 2,
+	2, 28, 120, 3, 2, 2, 2, 30, 122, 3, 2, 2, 2, 32, 125, 3, 2, 2, 2, 34, 127,
+	3, 2, 2, 2, 36, 37, 5, 14, 8, 2, 37, 38, 7, 2, 2, 3, 38, 3, 3, 2, 2, 2,
+	39, 40, 9, 2, 2, 2, 40, 5, 3, 2, 2, 2, 41, 49, 5, 18, 10, 2, 42, 46, 5,
+	4, 3, 2, 43, 45, 7, 14, 2, 2, 44, 43, 3, 2, 2, 2, 45, 48, 3, 2, 2, 2, 46,
+	44, 3, 2, 2, 2, 46, 47, 3, 2, 2, 2, 47, 50, 3, 2, 2, 2, 48, 46, 3, 2, 2,
+	2, 49, 42, 3, 2, 2, 2, 49, 50, 3, 2, 2, 2, 50, 7, 3, 2, 2, 2, 51, 52, 7,
+	3, 2, 2, 52, 9, 3, 2, 2, 2, 53, 54, 7, 4, 2, 2, 54, 11, 3, 2, 2, 2, 55,
+	59, 5, 8, 5, 2, 56, 58, 7, 14, 2, 2, 57, 56, 3, 2, 2, 2, 58, 61, 3, 2,
+	2, 2, 59, 57, 3, 2, 2, 2, 59, 60, 3, 2, 2, 2, 60, 65, 3, 2, 2, 2, 61, 59,
+	3, 2, 2, 2, 62, 64, 5, 6, 4, 2, 63, 62, 3, 2, 2, 2, 64, 67, 3, 2, 2, 2,
+	65, 63, 3, 2, 2, 2, 65, 66, 3, 2, 2, 2, 66, 68, 3, 2, 2, 2, 67, 65, 3,
+	2, 2, 2, 68, 69, 5, 10, 6, 2, 69, 13, 3, 2, 2, 2, 70, 72, 7, 14, 2, 2,
+	71, 70, 3, 2, 2, 2, 72, 75, 3, 2, 2, 2, 73, 71, 3, 2, 2, 2, 73, 74, 3,
+	2, 2, 2, 74, 76, 3, 2, 2, 2, 75, 73, 3, 2, 2, 2, 76, 80, 5, 12, 7, 2, 77,
+	79, 7, 14, 2, 2, 78, 77, 3, 2, 2, 2, 79, 82, 3, 2, 2, 2, 80, 78, 3, 2,
+	2, 2, 80, 81, 3, 2, 2, 2, 81, 83, 3, 2, 2, 2, 82, 80, 3, 2, 2, 2, 83, 84,
+	7, 2, 2, 3, 84, 15, 3, 2, 2, 2, 85, 87, 7, 14, 2, 2, 86, 85, 3, 2, 2, 2,
+	87, 90, 3, 2, 2, 2, 88, 86, 3, 2, 2, 2, 88, 89, 3, 2, 2, 2, 89, 94, 3,
+	2, 2, 2, 90, 88, 3, 2, 2, 2, 91, 93, 5, 6, 4, 2, 92, 91, 3, 2, 2, 2, 93,
+	96, 3, 2, 2, 2, 94, 92, 3, 2, 2, 2, 94, 95, 3, 2, 2, 2, 95, 97, 3, 2, 2,
+	2, 96, 94, 3, 2, 2, 2, 97, 98, 7, 2, 2, 3, 98, 17, 3, 2, 2, 2, 99, 103,
+	5, 20, 11, 2, 100, 102, 5, 22, 12, 2, 101, 100, 3, 2, 2, 2, 102, 105, 3,
+	2, 2, 2, 103, 101, 3, 2, 2, 2, 103, 104, 3, 2, 2, 2, 104, 19, 3, 2, 2,
+	2, 105, 103, 3, 2, 2, 2, 106, 107, 7, 8, 2, 2, 107, 21, 3, 2, 2, 2, 108,
+	115, 5, 24, 13, 2, 109, 115, 5, 26, 14, 2, 110, 115, 5, 28, 15, 2, 111,
+	115, 5, 30, 16, 2, 112, 115, 5, 32, 17, 2, 113, 115, 5, 34, 18, 2, 114,
+	108, 3, 2, 2, 2, 114, 109, 3, 2, 2, 2, 114, 110, 3, 2, 2, 2, 114, 111,
+	3, 2, 2, 2, 114, 112, 3, 2, 2, 2, 114, 113, 3, 2, 2, 2, 115, 23, 3, 2,
+	2, 2, 116, 117, 7, 8, 2, 2, 117, 25, 3, 2, 2, 2, 118, 119, 7, 9, 2, 2,
+	119, 27, 3, 2, 2, 2, 120, 121, 9, 3, 2, 2, 121, 29, 3, 2, 2, 2, 122, 123,
+	7, 5, 2, 2, 123, 124, 7, 8, 2, 2, 124, 31, 3, 2, 2, 2, 125, 126, 5, 12,
+	7, 2, 126, 33, 3, 2, 2, 2, 127, 132, 7, 6, 2, 2, 128, 131, 5, 22, 12, 2,
+	129, 131, 7, 14, 2, 2, 130, 128, 3, 2, 2, 2, 130, 129, 3, 2, 2, 2, 131,
+	134, 3, 2, 2, 2, 132, 130, 3, 2, 2, 2, 132, 133, 3, 2, 2, 2, 133, 135,
+	3, 2, 2, 2, 134, 132, 3, 2, 2, 2, 135, 136, 7, 7, 2, 2, 136, 35, 3, 2,
+	2, 2, 14, 46, 49, 59, 65, 73, 80, 88, 94, 103, 114, 130, 132,
 }
 var deserializer = antlr.NewATNDeserializer(nil)
 var deserializedATN = deserializer.DeserializeFromUInt16(parserATN)
@@ -90,13 +80,15 @@ var literalNames = []string{
 	"", "'{'", "'}'", "'$'", "'['", "']'",
 }
 var symbolicNames = []string{
-	"", "", "", "", "", "", "IDENTIFIER", "NUMBER", "TERMINATOR", "NL", "WS",
+	"", "", "", "", "", "", "IDENTIFIER", "NUMBER", "STRING", "RAW_STRING",
+	"UNTERMINATED_STRING", "TERMINATOR", "NL", "WS", "BLOCK_COMMENT", "UNTERMINATED_COMMENT",
+	"LINE_COMMENT",
 }
 
 var ruleNames = []string{
 	"start", "terminator", "commandListItem", "openBlock", "closeBlock", "commandBlock",
-	"commandBlockTail", "script", "singleCommand", "commandLine", "commandName",
-	"arguments", "namedArgument", "numericArgument", "variableArgument", "scriptArgument",
+	"script", "program", "singleCommand", "commandName", "argument", "namedArgument",
+	"numericArgument", "textArgument", "variableArgument", "scriptArgument",
 	"listArgument",
 }
 var decisionToDFA = make([]*antlr.DFA, len(deserializedATN.DecisionToState))
@@ -127,17 +119,23 @@ func NewGShellParser(input antlr.TokenStream) *GShellParser {
 
 // GShellParser tokens.
 const (
-	GShellParserEOF        = antlr.TokenEOF
-	GShellParserT__0       = 1
-	GShellParserT__1       = 2
-	GShellParserT__2       = 3
-	GShellParserT__3       = 4
-	GShellParserT__4       = 5
-	GShellParserIDENTIFIER = 6
-	GShellParserNUMBER     = 7
-	GShellParserTERMINATOR = 8
-	GShellParserNL         = 9
-	GShellParserWS         = 10
+	GShellParserEOF                  = antlr.TokenEOF
+	GShellParserT__0                 = 1
+	GShellParserT__1                 = 2
+	GShellParserT__2                 = 3
+	GShellParserT__3                 = 4
+	GShellParserT__4                 = 5
+	GShellParserIDENTIFIER           = 6
+	GShellParserNUMBER               = 7
+	GShellParserSTRING               = 8
+	GShellParserRAW_STRING           = 9
+	GShellParserUNTERMINATED_STRING  = 10
+	GShellParserTERMINATOR           = 11
+	GShellParserNL                   = 12
+	GShellParserWS                   = 13
+	GShellParserBLOCK_COMMENT        = 14
+	GShellParserUNTERMINATED_COMMENT = 15
+	GShellParserLINE_COMMENT         = 16
 )
 
 // GShellParser rules.
@@ -148,14 +146,14 @@ const (
 	GShellParserRULE_openBlock        = 3
 	GShellParserRULE_closeBlock       = 4
 	GShellParserRULE_commandBlock     = 5
-	GShellParserRULE_commandBlockTail = 6
-	GShellParserRULE_script           = 7
+	GShellParserRULE_script           = 6
+	GShellParserRULE_program          = 7
 	GShellParserRULE_singleCommand    = 8
-	GShellParserRULE_commandLine      = 9
-	GShellParserRULE_commandName      = 10
-	GShellParserRULE_arguments        = 11
-	GShellParserRULE_namedArgument    = 12
-	GShellParserRULE_numericArgument  = 13
+	GShellParserRULE_commandName      = 9
+	GShellParserRULE_argument         = 10
+	GShellParserRULE_namedArgument    = 11
+	GShellParserRULE_numericArgument  = 12
+	GShellParserRULE_textArgument     = 13
 	GShellParserRULE_variableArgument = 14
 	GShellParserRULE_scriptArgument   = 15
 	GShellParserRULE_listArgument     = 16
@@ -477,41 +475,34 @@ func (p *GShellParser) CommandListItem() (localctx ICommandListItemContext) {
 	}()
 
 	p.EnterOuterAlt(localctx, 1)
-	p.SetState(42)
+	{
+		p.SetState(39)
+		p.SingleCommand()
+	}
+	p.SetState(47)
 	p.GetErrorHandler().Sync(p)
 	_la = p.GetTokenStream().LA(1)
 
-	for _la == GShellParserNL {
+	if _la == GShellParserTERMINATOR || _la == GShellParserNL {
 		{
-			p.SetState(39)
-			p.Match(GShellParserNL)
+			p.SetState(40)
+			p.Terminator()
 		}
-
 		p.SetState(44)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
-	}
-	{
-		p.SetState(45)
-		p.SingleCommand()
-	}
-	{
-		p.SetState(46)
-		p.Terminator()
-	}
-	p.SetState(50)
-	p.GetErrorHandler().Sync(p)
-	_la = p.GetTokenStream().LA(1)
 
-	for _la == GShellParserNL {
-		{
-			p.SetState(47)
-			p.Match(GShellParserNL)
+		for _la == GShellParserNL {
+			{
+				p.SetState(41)
+				p.Match(GShellParserNL)
+			}
+
+			p.SetState(46)
+			p.GetErrorHandler().Sync(p)
+			_la = p.GetTokenStream().LA(1)
 		}
 
-		p.SetState(52)
-		p.GetErrorHandler().Sync(p)
-		_la = p.GetTokenStream().LA(1)
 	}
 
 	return localctx
@@ -596,7 +587,7 @@ func (p *GShellParser) OpenBlock() (localctx IOpenBlockContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(53)
+		p.SetState(49)
 		p.Match(GShellParserT__0)
 	}
 
@@ -682,7 +673,7 @@ func (p *GShellParser) CloseBlock() (localctx ICloseBlockContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(55)
+		p.SetState(51)
 		p.Match(GShellParserT__1)
 	}
 
@@ -737,178 +728,70 @@ func (s *CommandBlockContext) OpenBlock() IOpenBlockContext {
 	return t.(IOpenBlockContext)
 }
 
-func (s *CommandBlockContext) CommandBlockTail() ICommandBlockTailContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandBlockTailContext)(nil)).Elem(), 0)
+func (s *CommandBlockContext) CloseBlock() ICloseBlockContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICloseBlockContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(ICommandBlockTailContext)
-}
-
-func (s *CommandBlockContext) GetRuleContext() antlr.RuleContext {
-	return s
-}
-
-func (s *CommandBlockContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
-	return antlr.TreesStringTree(s, ruleNames, recog)
+	return t.(ICloseBlockContext)
 }
 
-func (s *CommandBlockContext) EnterRule(listener antlr.ParseTreeListener) {
-	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.EnterCommandBlock(s)
-	}
+func (s *CommandBlockContext) AllNL() []antlr.TerminalNode {
+	return s.GetTokens(GShellParserNL)
 }
 
-func (s *CommandBlockContext) ExitRule(listener antlr.ParseTreeListener) {
-	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.ExitCommandBlock(s)
-	}
+func (s *CommandBlockContext) NL(i int) antlr.TerminalNode {
+	return s.GetToken(GShellParserNL, i)
 }
 
-func (p *GShellParser) CommandBlock() (localctx ICommandBlockContext) {
-	localctx = NewCommandBlockContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 10, GShellParserRULE_commandBlock)
+func (s *CommandBlockContext) AllCommandListItem() []ICommandListItemContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*ICommandListItemContext)(nil)).Elem())
+	var tst = make([]ICommandListItemContext, len(ts))
 
-	defer func() {
-		p.ExitRule()
-	}()
-
-	defer func() {
-		if err := recover(); err != nil {
-			if v, ok := err.(antlr.RecognitionException); ok {
-				localctx.SetException(v)
-				p.GetErrorHandler().ReportError(p, v)
-				p.GetErrorHandler().Recover(p, v)
-			} else {
-				panic(err)
-			}
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(ICommandListItemContext)
 		}
-	}()
-
-	p.EnterOuterAlt(localctx, 1)
-	{
-		p.SetState(57)
-		p.OpenBlock()
 	}
-	{
-		p.SetState(58)
-		p.CommandBlockTail()
-	}
-
-	return localctx
-}
-
-// ICommandBlockTailContext is an interface to support dynamic dispatch.
-type ICommandBlockTailContext interface {
-	antlr.ParserRuleContext
-
-	// GetParser returns the parser.
-	GetParser() antlr.Parser
-
-	// IsCommandBlockTailContext differentiates from other interfaces.
-	IsCommandBlockTailContext()
-}
-
-type CommandBlockTailContext struct {
-	*antlr.BaseParserRuleContext
-	parser antlr.Parser
-}
-
-func NewEmptyCommandBlockTailContext() *CommandBlockTailContext {
-	var p = new(CommandBlockTailContext)
-	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
-	p.RuleIndex = GShellParserRULE_commandBlockTail
-	return p
-}
-
-func (*CommandBlockTailContext) IsCommandBlockTailContext() {}
-
-func NewCommandBlockTailContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *CommandBlockTailContext {
-	var p = new(CommandBlockTailContext)
-
-	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
-
-	p.parser = parser
-	p.RuleIndex = GShellParserRULE_commandBlockTail
-
-	return p
-}
-
-func (s *CommandBlockTailContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *CommandBlockTailContext) SingleCommand() ISingleCommandContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ISingleCommandContext)(nil)).Elem(), 0)
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(ISingleCommandContext)
-}
-
-func (s *CommandBlockTailContext) Terminator() ITerminatorContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ITerminatorContext)(nil)).Elem(), 0)
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(ITerminatorContext)
-}
-
-func (s *CommandBlockTailContext) CommandBlockTail() ICommandBlockTailContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandBlockTailContext)(nil)).Elem(), 0)
-
-	if t == nil {
-		return nil
-	}
-
-	return t.(ICommandBlockTailContext)
-}
-
-func (s *CommandBlockTailContext) AllNL() []antlr.TerminalNode {
-	return s.GetTokens(GShellParserNL)
-}
-
-func (s *CommandBlockTailContext) NL(i int) antlr.TerminalNode {
-	return s.GetToken(GShellParserNL, i)
+	return tst
 }
 
-func (s *CommandBlockTailContext) CloseBlock() ICloseBlockContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICloseBlockContext)(nil)).Elem(), 0)
+func (s *CommandBlockContext) CommandListItem(i int) ICommandListItemContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandListItemContext)(nil)).Elem(), i)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(ICloseBlockContext)
+	return t.(ICommandListItemContext)
 }
 
-func (s *CommandBlockTailContext) GetRuleContext() antlr.RuleContext {
+func (s *CommandBlockContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
 
-func (s *CommandBlockTailContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+func (s *CommandBlockContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
-func (s *CommandBlockTailContext) EnterRule(listener antlr.ParseTreeListener) {
+func (s *CommandBlockContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.EnterCommandBlockTail(s)
+		listenerT.EnterCommandBlock(s)
 	}
 }
 
-func (s *CommandBlockTailContext) ExitRule(listener antlr.ParseTreeListener) {
+func (s *CommandBlockContext) ExitRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.ExitCommandBlockTail(s)
+		listenerT.ExitCommandBlock(s)
 	}
 }
 
-func (p *GShellParser) CommandBlockTail() (localctx ICommandBlockTailContext) {
-	localctx = NewCommandBlockTailContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 12, GShellParserRULE_commandBlockTail)
+func (p *GShellParser) CommandBlock() (localctx ICommandBlockContext) {
+	localctx = NewCommandBlockContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 10, GShellParserRULE_commandBlock)
 	var _la int
 
 	defer func() {
@@ -927,102 +810,42 @@ func (p *GShellParser) CommandBlockTail() (localctx ICommandBlockTailContext) {
 		}
 	}()
 
-	var _alt int
-
-	p.SetState(92)
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(53)
+		p.OpenBlock()
+	}
+	p.SetState(57)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 6, p.GetParserRuleContext()) {
-	case 1:
-		p.EnterOuterAlt(localctx, 1)
-		p.SetState(63)
-		p.GetErrorHandler().Sync(p)
-		_la = p.GetTokenStream().LA(1)
-
-		for _la == GShellParserNL {
-			{
-				p.SetState(60)
-				p.Match(GShellParserNL)
-			}
-
-			p.SetState(65)
-			p.GetErrorHandler().Sync(p)
-			_la = p.GetTokenStream().LA(1)
-		}
-		{
-			p.SetState(66)
-			p.SingleCommand()
-		}
-		{
-			p.SetState(67)
-			p.Terminator()
-		}
-		p.SetState(71)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(68)
-					p.Match(GShellParserNL)
-				}
+	_la = p.GetTokenStream().LA(1)
 
-			}
-			p.SetState(73)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 3, p.GetParserRuleContext())
-		}
+	for _la == GShellParserNL {
 		{
-			p.SetState(74)
-			p.CommandBlockTail()
+			p.SetState(54)
+			p.Match(GShellParserNL)
 		}
 
-	case 2:
-		p.EnterOuterAlt(localctx, 2)
-		p.SetState(79)
+		p.SetState(59)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
+	}
+	p.SetState(63)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
 
-		for _la == GShellParserNL {
-			{
-				p.SetState(76)
-				p.Match(GShellParserNL)
-			}
-
-			p.SetState(81)
-			p.GetErrorHandler().Sync(p)
-			_la = p.GetTokenStream().LA(1)
-		}
+	for _la == GShellParserIDENTIFIER {
 		{
-			p.SetState(82)
-			p.SingleCommand()
+			p.SetState(60)
+			p.CommandListItem()
 		}
-		p.SetState(86)
+
+		p.SetState(65)
 		p.GetErrorHandler().Sync(p)
 		_la = p.GetTokenStream().LA(1)
-
-		for _la == GShellParserNL {
-			{
-				p.SetState(83)
-				p.Match(GShellParserNL)
-			}
-
-			p.SetState(88)
-			p.GetErrorHandler().Sync(p)
-			_la = p.GetTokenStream().LA(1)
-		}
-		{
-			p.SetState(89)
-			p.CloseBlock()
-		}
-
-	case 3:
-		p.EnterOuterAlt(localctx, 3)
-		{
-			p.SetState(91)
-			p.CloseBlock()
-		}
-
+	}
+	{
+		p.SetState(66)
+		p.CloseBlock()
 	}
 
 	return localctx
@@ -1080,6 +903,14 @@ func (s *ScriptContext) EOF() antlr.TerminalNode {
 	return s.GetToken(GShellParserEOF, 0)
 }
 
+func (s *ScriptContext) AllNL() []antlr.TerminalNode {
+	return s.GetTokens(GShellParserNL)
+}
+
+func (s *ScriptContext) NL(i int) antlr.TerminalNode {
+	return s.GetToken(GShellParserNL, i)
+}
+
 func (s *ScriptContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
@@ -1102,7 +933,8 @@ func (s *ScriptContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *GShellParser) Script() (localctx IScriptContext) {
 	localctx = NewScriptContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 14, GShellParserRULE_script)
+	p.EnterRule(localctx, 12, GShellParserRULE_script)
+	var _la int
 
 	defer func() {
 		p.ExitRule()
@@ -1121,89 +953,143 @@ func (p *GShellParser) Script() (localctx IScriptContext) {
 	}()
 
 	p.EnterOuterAlt(localctx, 1)
+	p.SetState(71)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for _la == GShellParserNL {
+		{
+			p.SetState(68)
+			p.Match(GShellParserNL)
+		}
+
+		p.SetState(73)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
 	{
-		p.SetState(94)
+		p.SetState(74)
 		p.CommandBlock()
 	}
+	p.SetState(78)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for _la == GShellParserNL {
+		{
+			p.SetState(75)
+			p.Match(GShellParserNL)
+		}
+
+		p.SetState(80)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
 	{
-		p.SetState(95)
+		p.SetState(81)
 		p.Match(GShellParserEOF)
 	}
 
 	return localctx
 }
 
-// ISingleCommandContext is an interface to support dynamic dispatch.
-type ISingleCommandContext interface {
+// IProgramContext is an interface to support dynamic dispatch.
+type IProgramContext interface {
 	antlr.ParserRuleContext
 
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// IsSingleCommandContext differentiates from other interfaces.
-	IsSingleCommandContext()
+	// IsProgramContext differentiates from other interfaces.
+	IsProgramContext()
 }
 
-type SingleCommandContext struct {
+type ProgramContext struct {
 	*antlr.BaseParserRuleContext
 	parser antlr.Parser
 }
 
-func NewEmptySingleCommandContext() *SingleCommandContext {
-	var p = new(SingleCommandContext)
+func NewEmptyProgramContext() *ProgramContext {
+	var p = new(ProgramContext)
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
-	p.RuleIndex = GShellParserRULE_singleCommand
+	p.RuleIndex = GShellParserRULE_program
 	return p
 }
 
-func (*SingleCommandContext) IsSingleCommandContext() {}
+func (*ProgramContext) IsProgramContext() {}
 
-func NewSingleCommandContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *SingleCommandContext {
-	var p = new(SingleCommandContext)
+func NewProgramContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ProgramContext {
+	var p = new(ProgramContext)
 
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
 
 	p.parser = parser
-	p.RuleIndex = GShellParserRULE_singleCommand
+	p.RuleIndex = GShellParserRULE_program
 
 	return p
 }
 
-func (s *SingleCommandContext) GetParser() antlr.Parser { return s.parser }
+func (s *ProgramContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *ProgramContext) EOF() antlr.TerminalNode {
+	return s.GetToken(GShellParserEOF, 0)
+}
+
+func (s *ProgramContext) AllNL() []antlr.TerminalNode {
+	return s.GetTokens(GShellParserNL)
+}
+
+func (s *ProgramContext) NL(i int) antlr.TerminalNode {
+	return s.GetToken(GShellParserNL, i)
+}
+
+func (s *ProgramContext) AllCommandListItem() []ICommandListItemContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*ICommandListItemContext)(nil)).Elem())
+	var tst = make([]ICommandListItemContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(ICommandListItemContext)
+		}
+	}
+
+	return tst
+}
 
-func (s *SingleCommandContext) CommandLine() ICommandLineContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandLineContext)(nil)).Elem(), 0)
+func (s *ProgramContext) CommandListItem(i int) ICommandListItemContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandListItemContext)(nil)).Elem(), i)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(ICommandLineContext)
+	return t.(ICommandListItemContext)
 }
 
-func (s *SingleCommandContext) GetRuleContext() antlr.RuleContext {
+func (s *ProgramContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
 
-func (s *SingleCommandContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+func (s *ProgramContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
-func (s *SingleCommandContext) EnterRule(listener antlr.ParseTreeListener) {
+func (s *ProgramContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.EnterSingleCommand(s)
+		listenerT.EnterProgram(s)
 	}
 }
 
-func (s *SingleCommandContext) ExitRule(listener antlr.ParseTreeListener) {
+func (s *ProgramContext) ExitRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.ExitSingleCommand(s)
+		listenerT.ExitProgram(s)
 	}
 }
 
-func (p *GShellParser) SingleCommand() (localctx ISingleCommandContext) {
-	localctx = NewSingleCommandContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 16, GShellParserRULE_singleCommand)
+func (p *GShellParser) Program() (localctx IProgramContext) {
+	localctx = NewProgramContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 14, GShellParserRULE_program)
+	var _la int
 
 	defer func() {
 		p.ExitRule()
@@ -1222,53 +1108,81 @@ func (p *GShellParser) SingleCommand() (localctx ISingleCommandContext) {
 	}()
 
 	p.EnterOuterAlt(localctx, 1)
+	p.SetState(86)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for _la == GShellParserNL {
+		{
+			p.SetState(83)
+			p.Match(GShellParserNL)
+		}
+
+		p.SetState(88)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
+	p.SetState(92)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for _la == GShellParserIDENTIFIER {
+		{
+			p.SetState(89)
+			p.CommandListItem()
+		}
+
+		p.SetState(94)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
+	}
 	{
-		p.SetState(97)
-		p.CommandLine()
+		p.SetState(95)
+		p.Match(GShellParserEOF)
 	}
 
 	return localctx
 }
 
-// ICommandLineContext is an interface to support dynamic dispatch.
-type ICommandLineContext interface {
+// ISingleCommandContext is an interface to support dynamic dispatch.
+type ISingleCommandContext interface {
 	antlr.ParserRuleContext
 
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// IsCommandLineContext differentiates from other interfaces.
-	IsCommandLineContext()
+	// IsSingleCommandContext differentiates from other interfaces.
+	IsSingleCommandContext()
 }
 
-type CommandLineContext struct {
+type SingleCommandContext struct {
 	*antlr.BaseParserRuleContext
 	parser antlr.Parser
 }
 
-func NewEmptyCommandLineContext() *CommandLineContext {
-	var p = new(CommandLineContext)
+func NewEmptySingleCommandContext() *SingleCommandContext {
+	var p = new(SingleCommandContext)
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
-	p.RuleIndex = GShellParserRULE_commandLine
+	p.RuleIndex = GShellParserRULE_singleCommand
 	return p
 }
 
-func (*CommandLineContext) IsCommandLineContext() {}
+func (*SingleCommandContext) IsSingleCommandContext() {}
 
-func NewCommandLineContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *CommandLineContext {
-	var p = new(CommandLineContext)
+func NewSingleCommandContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *SingleCommandContext {
+	var p = new(SingleCommandContext)
 
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
 
 	p.parser = parser
-	p.RuleIndex = GShellParserRULE_commandLine
+	p.RuleIndex = GShellParserRULE_singleCommand
 
 	return p
 }
 
-func (s *CommandLineContext) GetParser() antlr.Parser { return s.parser }
+func (s *SingleCommandContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *CommandLineContext) CommandName() ICommandNameContext {
+func (s *SingleCommandContext) CommandName() ICommandNameContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*ICommandNameContext)(nil)).Elem(), 0)
 
 	if t == nil {
@@ -1278,39 +1192,52 @@ func (s *CommandLineContext) CommandName() ICommandNameContext {
 	return t.(ICommandNameContext)
 }
 
-func (s *CommandLineContext) Arguments() IArgumentsContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*IArgumentsContext)(nil)).Elem(), 0)
+func (s *SingleCommandContext) AllArgument() []IArgumentContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IArgumentContext)(nil)).Elem())
+	var tst = make([]IArgumentContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IArgumentContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *SingleCommandContext) Argument(i int) IArgumentContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IArgumentContext)(nil)).Elem(), i)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(IArgumentsContext)
+	return t.(IArgumentContext)
 }
 
-func (s *CommandLineContext) GetRuleContext() antlr.RuleContext {
+func (s *SingleCommandContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
 
-func (s *CommandLineContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+func (s *SingleCommandContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
-func (s *CommandLineContext) EnterRule(listener antlr.ParseTreeListener) {
+func (s *SingleCommandContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.EnterCommandLine(s)
+		listenerT.EnterSingleCommand(s)
 	}
 }
 
-func (s *CommandLineContext) ExitRule(listener antlr.ParseTreeListener) {
+func (s *SingleCommandContext) ExitRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.ExitCommandLine(s)
+		listenerT.ExitSingleCommand(s)
 	}
 }
 
-func (p *GShellParser) CommandLine() (localctx ICommandLineContext) {
-	localctx = NewCommandLineContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 18, GShellParserRULE_commandLine)
+func (p *GShellParser) SingleCommand() (localctx ISingleCommandContext) {
+	localctx = NewSingleCommandContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 16, GShellParserRULE_singleCommand)
 
 	defer func() {
 		p.ExitRule()
@@ -1328,27 +1255,28 @@ func (p *GShellParser) CommandLine() (localctx ICommandLineContext) {
 		}
 	}()
 
-	p.SetState(103)
+	var _alt int
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(97)
+		p.CommandName()
+	}
+	p.SetState(101)
 	p.GetErrorHandler().Sync(p)
-	switch p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 7, p.GetParserRuleContext()) {
-	case 1:
-		p.EnterOuterAlt(localctx, 1)
-		{
-			p.SetState(99)
-			p.CommandName()
-		}
+	_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 8, p.GetParserRuleContext())
 
-	case 2:
-		p.EnterOuterAlt(localctx, 2)
-		{
-			p.SetState(100)
-			p.CommandName()
-		}
-		{
-			p.SetState(101)
-			p.Arguments()
-		}
+	for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
+		if _alt == 1 {
+			{
+				p.SetState(98)
+				p.Argument()
+			}
 
+		}
+		p.SetState(103)
+		p.GetErrorHandler().Sync(p)
+		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 8, p.GetParserRuleContext())
 	}
 
 	return localctx
@@ -1418,7 +1346,7 @@ func (s *CommandNameContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *GShellParser) CommandName() (localctx ICommandNameContext) {
 	localctx = NewCommandNameContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 20, GShellParserRULE_commandName)
+	p.EnterRule(localctx, 18, GShellParserRULE_commandName)
 
 	defer func() {
 		p.ExitRule()
@@ -1438,52 +1366,52 @@ func (p *GShellParser) CommandName() (localctx ICommandNameContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(105)
+		p.SetState(104)
 		p.Match(GShellParserIDENTIFIER)
 	}
 
 	return localctx
 }
 
-// IArgumentsContext is an interface to support dynamic dispatch.
-type IArgumentsContext interface {
+// IArgumentContext is an interface to support dynamic dispatch.
+type IArgumentContext interface {
 	antlr.ParserRuleContext
 
 	// GetParser returns the parser.
 	GetParser() antlr.Parser
 
-	// IsArgumentsContext differentiates from other interfaces.
-	IsArgumentsContext()
+	// IsArgumentContext differentiates from other interfaces.
+	IsArgumentContext()
 }
 
-type ArgumentsContext struct {
+type ArgumentContext struct {
 	*antlr.BaseParserRuleContext
 	parser antlr.Parser
 }
 
-func NewEmptyArgumentsContext() *ArgumentsContext {
-	var p = new(ArgumentsContext)
+func NewEmptyArgumentContext() *ArgumentContext {
+	var p = new(ArgumentContext)
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
-	p.RuleIndex = GShellParserRULE_arguments
+	p.RuleIndex = GShellParserRULE_argument
 	return p
 }
 
-func (*ArgumentsContext) IsArgumentsContext() {}
+func (*ArgumentContext) IsArgumentContext() {}
 
-func NewArgumentsContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ArgumentsContext {
-	var p = new(ArgumentsContext)
+func NewArgumentContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *ArgumentContext {
+	var p = new(ArgumentContext)
 
 	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
 
 	p.parser = parser
-	p.RuleIndex = GShellParserRULE_arguments
+	p.RuleIndex = GShellParserRULE_argument
 
 	return p
 }
 
-func (s *ArgumentsContext) GetParser() antlr.Parser { return s.parser }
+func (s *ArgumentContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *ArgumentsContext) NamedArgument() INamedArgumentContext {
+func (s *ArgumentContext) NamedArgument() INamedArgumentContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*INamedArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
@@ -1493,40 +1421,27 @@ func (s *ArgumentsContext) NamedArgument() INamedArgumentContext {
 	return t.(INamedArgumentContext)
 }
 
-func (s *ArgumentsContext) AllArguments() []IArgumentsContext {
-	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IArgumentsContext)(nil)).Elem())
-	var tst = make([]IArgumentsContext, len(ts))
-
-	for i, t := range ts {
-		if t != nil {
-			tst[i] = t.(IArgumentsContext)
-		}
-	}
-
-	return tst
-}
-
-func (s *ArgumentsContext) Arguments(i int) IArgumentsContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*IArgumentsContext)(nil)).Elem(), i)
+func (s *ArgumentContext) NumericArgument() INumericArgumentContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*INumericArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(IArgumentsContext)
+	return t.(INumericArgumentContext)
 }
 
-func (s *ArgumentsContext) NumericArgument() INumericArgumentContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*INumericArgumentContext)(nil)).Elem(), 0)
+func (s *ArgumentContext) TextArgument() ITextArgumentContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*ITextArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(INumericArgumentContext)
+	return t.(ITextArgumentContext)
 }
 
-func (s *ArgumentsContext) VariableArgument() IVariableArgumentContext {
+func (s *ArgumentContext) VariableArgument() IVariableArgumentContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*IVariableArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
@@ -1536,7 +1451,7 @@ func (s *ArgumentsContext) VariableArgument() IVariableArgumentContext {
 	return t.(IVariableArgumentContext)
 }
 
-func (s *ArgumentsContext) ScriptArgument() IScriptArgumentContext {
+func (s *ArgumentContext) ScriptArgument() IScriptArgumentContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*IScriptArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
@@ -1546,7 +1461,7 @@ func (s *ArgumentsContext) ScriptArgument() IScriptArgumentContext {
 	return t.(IScriptArgumentContext)
 }
 
-func (s *ArgumentsContext) ListArgument() IListArgumentContext {
+func (s *ArgumentContext) ListArgument() IListArgumentContext {
 	var t = s.GetTypedRuleContext(reflect.TypeOf((*IListArgumentContext)(nil)).Elem(), 0)
 
 	if t == nil {
@@ -1556,29 +1471,29 @@ func (s *ArgumentsContext) ListArgument() IListArgumentContext {
 	return t.(IListArgumentContext)
 }
 
-func (s *ArgumentsContext) GetRuleContext() antlr.RuleContext {
+func (s *ArgumentContext) GetRuleContext() antlr.RuleContext {
 	return s
 }
 
-func (s *ArgumentsContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+func (s *ArgumentContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
 	return antlr.TreesStringTree(s, ruleNames, recog)
 }
 
-func (s *ArgumentsContext) EnterRule(listener antlr.ParseTreeListener) {
+func (s *ArgumentContext) EnterRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.EnterArguments(s)
+		listenerT.EnterArgument(s)
 	}
 }
 
-func (s *ArgumentsContext) ExitRule(listener antlr.ParseTreeListener) {
+func (s *ArgumentContext) ExitRule(listener antlr.ParseTreeListener) {
 	if listenerT, ok := listener.(GShellListener); ok {
-		listenerT.ExitArguments(s)
+		listenerT.ExitArgument(s)
 	}
 }
 
-func (p *GShellParser) Arguments() (localctx IArgumentsContext) {
-	localctx = NewArgumentsContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 22, GShellParserRULE_arguments)
+func (p *GShellParser) Argument() (localctx IArgumentContext) {
+	localctx = NewArgumentContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 20, GShellParserRULE_argument)
 
 	defer func() {
 		p.ExitRule()
@@ -1596,126 +1511,51 @@ func (p *GShellParser) Arguments() (localctx IArgumentsContext) {
 		}
 	}()
 
-	var _alt int
-
-	p.SetState(142)
+	p.SetState(112)
 	p.GetErrorHandler().Sync(p)
 
 	switch p.GetTokenStream().LA(1) {
 	case GShellParserIDENTIFIER:
 		p.EnterOuterAlt(localctx, 1)
 		{
-			p.SetState(107)
+			p.SetState(106)
 			p.NamedArgument()
 		}
-		p.SetState(111)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 8, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(108)
-					p.Arguments()
-				}
-
-			}
-			p.SetState(113)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 8, p.GetParserRuleContext())
-		}
 
 	case GShellParserNUMBER:
 		p.EnterOuterAlt(localctx, 2)
 		{
-			p.SetState(114)
+			p.SetState(107)
 			p.NumericArgument()
 		}
-		p.SetState(118)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 9, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(115)
-					p.Arguments()
-				}
 
-			}
-			p.SetState(120)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 9, p.GetParserRuleContext())
+	case GShellParserSTRING, GShellParserRAW_STRING, GShellParserUNTERMINATED_STRING:
+		p.EnterOuterAlt(localctx, 3)
+		{
+			p.SetState(108)
+			p.TextArgument()
 		}
 
 	case GShellParserT__2:
-		p.EnterOuterAlt(localctx, 3)
+		p.EnterOuterAlt(localctx, 4)
 		{
-			p.SetState(121)
+			p.SetState(109)
 			p.VariableArgument()
 		}
-		p.SetState(125)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 10, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(122)
-					p.Arguments()
-				}
-
-			}
-			p.SetState(127)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 10, p.GetParserRuleContext())
-		}
 
 	case GShellParserT__0:
-		p.EnterOuterAlt(localctx, 4)
+		p.EnterOuterAlt(localctx, 5)
 		{
-			p.SetState(128)
+			p.SetState(110)
 			p.ScriptArgument()
 		}
-		p.SetState(132)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 11, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(129)
-					p.Arguments()
-				}
-
-			}
-			p.SetState(134)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 11, p.GetParserRuleContext())
-		}
 
 	case GShellParserT__3:
-		p.EnterOuterAlt(localctx, 5)
+		p.EnterOuterAlt(localctx, 6)
 		{
-			p.SetState(135)
+			p.SetState(111)
 			p.ListArgument()
 		}
-		p.SetState(139)
-		p.GetErrorHandler().Sync(p)
-		_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 12, p.GetParserRuleContext())
-
-		for _alt != 2 && _alt != antlr.ATNInvalidAltNumber {
-			if _alt == 1 {
-				{
-					p.SetState(136)
-					p.Arguments()
-				}
-
-			}
-			p.SetState(141)
-			p.GetErrorHandler().Sync(p)
-			_alt = p.GetInterpreter().AdaptivePredict(p.GetTokenStream(), 12, p.GetParserRuleContext())
-		}
 
 	default:
 		panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
@@ -1788,7 +1628,7 @@ func (s *NamedArgumentContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *GShellParser) NamedArgument() (localctx INamedArgumentContext) {
 	localctx = NewNamedArgumentContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 24, GShellParserRULE_namedArgument)
+	p.EnterRule(localctx, 22, GShellParserRULE_namedArgument)
 
 	defer func() {
 		p.ExitRule()
@@ -1808,7 +1648,7 @@ func (p *GShellParser) NamedArgument() (localctx INamedArgumentContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(144)
+		p.SetState(114)
 		p.Match(GShellParserIDENTIFIER)
 	}
 
@@ -1879,7 +1719,7 @@ func (s *NumericArgumentContext) ExitRule(listener antlr.ParseTreeListener) {
 
 func (p *GShellParser) NumericArgument() (localctx INumericArgumentContext) {
 	localctx = NewNumericArgumentContext(p, p.GetParserRuleContext(), p.GetState())
-	p.EnterRule(localctx, 26, GShellParserRULE_numericArgument)
+	p.EnterRule(localctx, 24, GShellParserRULE_numericArgument)
 
 	defer func() {
 		p.ExitRule()
@@ -1899,13 +1739,120 @@ func (p *GShellParser) NumericArgument() (localctx INumericArgumentContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(146)
+		p.SetState(116)
 		p.Match(GShellParserNUMBER)
 	}
 
 	return localctx
 }
 
+// ITextArgumentContext is an interface to support dynamic dispatch.
+type ITextArgumentContext interface {
+	antlr.ParserRuleContext
+
+	// GetParser returns the parser.
+	GetParser() antlr.Parser
+
+	// IsTextArgumentContext differentiates from other interfaces.
+	IsTextArgumentContext()
+}
+
+type TextArgumentContext struct {
+	*antlr.BaseParserRuleContext
+	parser antlr.Parser
+}
+
+func NewEmptyTextArgumentContext() *TextArgumentContext {
+	var p = new(TextArgumentContext)
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(nil, -1)
+	p.RuleIndex = GShellParserRULE_textArgument
+	return p
+}
+
+func (*TextArgumentContext) IsTextArgumentContext() {}
+
+func NewTextArgumentContext(parser antlr.Parser, parent antlr.ParserRuleContext, invokingState int) *TextArgumentContext {
+	var p = new(TextArgumentContext)
+
+	p.BaseParserRuleContext = antlr.NewBaseParserRuleContext(parent, invokingState)
+
+	p.parser = parser
+	p.RuleIndex = GShellParserRULE_textArgument
+
+	return p
+}
+
+func (s *TextArgumentContext) GetParser() antlr.Parser { return s.parser }
+
+func (s *TextArgumentContext) STRING() antlr.TerminalNode {
+	return s.GetToken(GShellParserSTRING, 0)
+}
+
+func (s *TextArgumentContext) RAW_STRING() antlr.TerminalNode {
+	return s.GetToken(GShellParserRAW_STRING, 0)
+}
+
+func (s *TextArgumentContext) UNTERMINATED_STRING() antlr.TerminalNode {
+	return s.GetToken(GShellParserUNTERMINATED_STRING, 0)
+}
+
+func (s *TextArgumentContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *TextArgumentContext) ToStringTree(ruleNames []string, recog antlr.Recognizer) string {
+	return antlr.TreesStringTree(s, ruleNames, recog)
+}
+
+func (s *TextArgumentContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(GShellListener); ok {
+		listenerT.EnterTextArgument(s)
+	}
+}
+
+func (s *TextArgumentContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(GShellListener); ok {
+		listenerT.ExitTextArgument(s)
+	}
+}
+
+func (p *GShellParser) TextArgument() (localctx ITextArgumentContext) {
+	localctx = NewTextArgumentContext(p, p.GetParserRuleContext(), p.GetState())
+	p.EnterRule(localctx, 26, GShellParserRULE_textArgument)
+	var _la int
+
+	defer func() {
+		p.ExitRule()
+	}()
+
+	defer func() {
+		if err := recover(); err != nil {
+			if v, ok := err.(antlr.RecognitionException); ok {
+				localctx.SetException(v)
+				p.GetErrorHandler().ReportError(p, v)
+				p.GetErrorHandler().Recover(p, v)
+			} else {
+				panic(err)
+			}
+		}
+	}()
+
+	p.EnterOuterAlt(localctx, 1)
+	{
+		p.SetState(118)
+		_la = p.GetTokenStream().LA(1)
+
+		if !(((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<GShellParserSTRING)|(1<<GShellParserRAW_STRING)|(1<<GShellParserUNTERMINATED_STRING))) != 0) {
+			p.GetErrorHandler().RecoverInline(p)
+		} else {
+			p.GetErrorHandler().ReportMatch(p)
+			p.Consume()
+		}
+	}
+
+	return localctx
+}
+
 // IVariableArgumentContext is an interface to support dynamic dispatch.
 type IVariableArgumentContext interface {
 	antlr.ParserRuleContext
@@ -1990,11 +1937,11 @@ func (p *GShellParser) VariableArgument() (localctx IVariableArgumentContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(148)
+		p.SetState(120)
 		p.Match(GShellParserT__2)
 	}
 	{
-		p.SetState(149)
+		p.SetState(121)
 		p.Match(GShellParserIDENTIFIER)
 	}
 
@@ -2091,7 +2038,7 @@ func (p *GShellParser) ScriptArgument() (localctx IScriptArgumentContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(151)
+		p.SetState(123)
 		p.CommandBlock()
 	}
 
@@ -2136,14 +2083,35 @@ func NewListArgumentContext(parser antlr.Parser, parent antlr.ParserRuleContext,
 
 func (s *ListArgumentContext) GetParser() antlr.Parser { return s.parser }
 
-func (s *ListArgumentContext) Arguments() IArgumentsContext {
-	var t = s.GetTypedRuleContext(reflect.TypeOf((*IArgumentsContext)(nil)).Elem(), 0)
+func (s *ListArgumentContext) AllArgument() []IArgumentContext {
+	var ts = s.GetTypedRuleContexts(reflect.TypeOf((*IArgumentContext)(nil)).Elem())
+	var tst = make([]IArgumentContext, len(ts))
+
+	for i, t := range ts {
+		if t != nil {
+			tst[i] = t.(IArgumentContext)
+		}
+	}
+
+	return tst
+}
+
+func (s *ListArgumentContext) Argument(i int) IArgumentContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IArgumentContext)(nil)).Elem(), i)
 
 	if t == nil {
 		return nil
 	}
 
-	return t.(IArgumentsContext)
+	return t.(IArgumentContext)
+}
+
+func (s *ListArgumentContext) AllNL() []antlr.TerminalNode {
+	return s.GetTokens(GShellParserNL)
+}
+
+func (s *ListArgumentContext) NL(i int) antlr.TerminalNode {
+	return s.GetToken(GShellParserNL, i)
 }
 
 func (s *ListArgumentContext) GetRuleContext() antlr.RuleContext {
@@ -2169,6 +2137,7 @@ func (s *ListArgumentContext) ExitRule(listener antlr.ParseTreeListener) {
 func (p *GShellParser) ListArgument() (localctx IListArgumentContext) {
 	localctx = NewListArgumentContext(p, p.GetParserRuleContext(), p.GetState())
 	p.EnterRule(localctx, 32, GShellParserRULE_listArgument)
+	var _la int
 
 	defer func() {
 		p.ExitRule()
@@ -2188,15 +2157,40 @@ func (p *GShellParser) ListArgument() (localctx IListArgumentContext) {
 
 	p.EnterOuterAlt(localctx, 1)
 	{
-		p.SetState(153)
+		p.SetState(125)
 		p.Match(GShellParserT__3)
 	}
-	{
-		p.SetState(154)
-		p.Arguments()
+	p.SetState(130)
+	p.GetErrorHandler().Sync(p)
+	_la = p.GetTokenStream().LA(1)
+
+	for ((_la)&-(0x1f+1)) == 0 && ((1<<uint(_la))&((1<<GShellParserT__0)|(1<<GShellParserT__2)|(1<<GShellParserT__3)|(1<<GShellParserIDENTIFIER)|(1<<GShellParserNUMBER)|(1<<GShellParserSTRING)|(1<<GShellParserRAW_STRING)|(1<<GShellParserUNTERMINATED_STRING)|(1<<GShellParserNL))) != 0 {
+		p.SetState(128)
+		p.GetErrorHandler().Sync(p)
+
+		switch p.GetTokenStream().LA(1) {
+		case GShellParserT__0, GShellParserT__2, GShellParserT__3, GShellParserIDENTIFIER, GShellParserNUMBER, GShellParserSTRING, GShellParserRAW_STRING, GShellParserUNTERMINATED_STRING:
+			{
+				p.SetState(126)
+				p.Argument()
+			}
+
+		case GShellParserNL:
+			{
+				p.SetState(127)
+				p.Match(GShellParserNL)
+			}
+
+		default:
+			panic(antlr.NewNoViableAltException(p, nil, nil, nil, nil, nil))
+		}
+
+		p.SetState(132)
+		p.GetErrorHandler().Sync(p)
+		_la = p.GetTokenStream().LA(1)
 	}
 	{
-		p.SetState(155)
+		p.SetState(133)
 		p.Match(GShellParserT__4)
 	}
 
@@ -0,0 +1,155 @@
+package legacy
+
+import (
+	"github.com/antlr/antlr4/runtime/Go/antlr"
+)
+
+type (
+	// errorStrategy recovers from a syntax error by skipping the
+	// rest of the command where it was found, so every error in
+	// the code is reported and the other commands are kept.
+	//
+	// Errors found between commands skip the whole line instead.
+	errorStrategy struct {
+		*antlr.DefaultErrorStrategy
+
+		// broken commands are dropped by the astBuilder
+		broken map[*SingleCommandContext]bool
+
+		// index of the last token reported, ANTLR might
+		// find the same problem again while leaving the rules
+		lastError int
+	}
+)
+
+// names of the literal tokens of the grammar
+const (
+	tokenOpenBlock  = GShellParserT__0
+	tokenCloseBlock = GShellParserT__1
+	tokenOpenList   = GShellParserT__3
+	tokenCloseList  = GShellParserT__4
+)
+
+func newErrorStrategy() *errorStrategy {
+	return &errorStrategy{
+		DefaultErrorStrategy: antlr.NewDefaultErrorStrategy(),
+		broken:               make(map[*SingleCommandContext]bool),
+		lastError:            -1,
+	}
+}
+
+// Sync is called by the parser before every loop and optional
+// rule, it checks if the next token can be parsed at all
+func (s *errorStrategy) Sync(p antlr.Parser) {
+	for {
+		tok := p.GetCurrentToken()
+		if tok.GetTokenType() == antlr.TokenEOF || p.IsExpectedToken(tok.GetTokenType()) {
+			return
+		}
+		if _, inCommand := enclosingCommand(p.GetParserRuleContext()); inCommand {
+			panic(antlr.NewInputMisMatchException(p))
+		}
+		s.report(p, tok, "extraneous input "+s.GetTokenErrorDisplay(tok)+" expecting "+
+			p.GetExpectedTokens().StringVerbose(p.GetLiteralNames(), p.GetSymbolicNames(), false))
+		if tok.GetTokenType() == tokenCloseBlock {
+			// skip would stop at a '}' which closes nothing
+			p.Consume()
+			continue
+		}
+		s.skip(p, true)
+	}
+}
+
+// RecoverInline is called when a token does not match, the
+// default strategy would insert or delete a single token
+// which hides the actual problem
+func (s *errorStrategy) RecoverInline(p antlr.Parser) antlr.Token {
+	panic(antlr.NewInputMisMatchException(p))
+}
+
+func (s *errorStrategy) ReportError(p antlr.Parser, e antlr.RecognitionException) {
+	if cmd, ok := enclosingCommand(p.GetParserRuleContext()); ok {
+		s.broken[cmd] = true
+	}
+	if e.GetOffendingToken().GetTokenIndex() == s.lastError {
+		return
+	}
+	s.lastError = e.GetOffendingToken().GetTokenIndex()
+	switch e := e.(type) {
+	case *antlr.NoViableAltException:
+		s.ReportNoViableAlternative(p, e)
+	case *antlr.InputMisMatchException:
+		s.ReportInputMisMatch(p, e)
+	default:
+		p.NotifyErrorListeners(e.GetMessage(), e.GetOffendingToken(), e)
+	}
+}
+
+// Recover is called by a rule after its error was reported,
+// the error is passed up until it reaches the command
+func (s *errorStrategy) Recover(p antlr.Parser, e antlr.RecognitionException) {
+	switch p.GetParserRuleContext().(type) {
+	case *SingleCommandContext:
+		s.skip(p, false)
+	case *StartContext, *ProgramContext:
+		for p.GetCurrentToken().GetTokenType() != antlr.TokenEOF {
+			p.Consume()
+		}
+	default:
+		panic(e)
+	}
+}
+
+func (s *errorStrategy) report(p antlr.Parser, tok antlr.Token, msg string) {
+	if tok.GetTokenIndex() == s.lastError {
+		return
+	}
+	s.lastError = tok.GetTokenIndex()
+	p.NotifyErrorListeners(msg, tok, nil)
+}
+
+// skip consumes the tokens up to the end of the command, blocks
+// and lists are skipped as a whole. The '}' closing the current
+// block is kept, the terminator is consumed only if consumeEnd is true
+func (s *errorStrategy) skip(p antlr.Parser, consumeEnd bool) {
+	depth := 0
+	for {
+		switch p.GetCurrentToken().GetTokenType() {
+		case antlr.TokenEOF:
+			return
+		case tokenOpenBlock, tokenOpenList:
+			depth++
+		case tokenCloseBlock:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case tokenCloseList:
+			if depth > 0 {
+				depth--
+			}
+		case GShellParserTERMINATOR, GShellParserNL:
+			if depth == 0 {
+				if consumeEnd {
+					p.Consume()
+				}
+				return
+			}
+		}
+		p.Consume()
+	}
+}
+
+// enclosingCommand returns the innermost command which contains
+// ctx, blocks given as arguments are not part of the command
+func enclosingCommand(ctx antlr.Tree) (*SingleCommandContext, bool) {
+	for ; ctx != nil; ctx = ctx.GetParent() {
+		switch ctx := ctx.(type) {
+		case *SingleCommandContext:
+			return ctx, true
+		case *CommandBlockContext:
+			return nil, false
+		}
+	}
+	return nil, false
+}
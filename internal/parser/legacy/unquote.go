@@ -0,0 +1,204 @@
+package legacy
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	partKind int
+
+	// stringPart is a piece of a double quoted string, either
+	// decoded text, the name of a variable or the source of a block.
+	// Variables and blocks keep their offsets in the literal
+	stringPart struct {
+		kind       partKind
+		text       string
+		start, end int
+	}
+)
+
+const (
+	partText partKind = iota
+	partVar
+	partBlock
+)
+
+// unquote splits a double quoted string literal into its parts.
+// Invalid escape sequences are kept as they are and the
+// first one is returned as an error along with the parts
+func unquote(lit string) ([]stringPart, error) {
+	if len(lit) < 2 || lit[0] != '"' || lit[len(lit)-1] != '"' {
+		return nil, errors.New("missing quotes")
+	}
+	// offsets skip the opening quote
+	lit = lit[1 : len(lit)-1]
+	var parts []stringPart
+	var invalid error
+	var value strings.Builder
+	flush := func() {
+		if value.Len() > 0 {
+			parts = append(parts, stringPart{kind: partText, text: value.String()})
+			value.Reset()
+		}
+	}
+	for i := 0; i < len(lit); i++ {
+		switch lit[i] {
+		case '\\':
+			r, size, err := escape(lit[i+1:])
+			if err != nil {
+				// keep going, so the string is still usable
+				if invalid == nil {
+					invalid = err
+				}
+				value.WriteByte('\\')
+				continue
+			}
+			value.WriteRune(r)
+			i += size
+		case '$':
+			switch {
+			case i+1 < len(lit) && lit[i+1] == '{':
+				end, ok := skipBlock(lit, i+1)
+				if !ok {
+					return nil, errors.New("unterminated interpolation block")
+				}
+				flush()
+				block := lit[i+1 : end]
+				part := stringPart{kind: partBlock, text: block, start: i + 1, end: end + 1}
+				if name := block[1 : len(block)-1]; isIdentifier(name) {
+					part.kind, part.text = partVar, name
+				}
+				parts = append(parts, part)
+				i = end - 1
+			case isIdentifierStart(firstRune(lit[i+1:])):
+				flush()
+				end := i + 1
+				// '$' starts the next interpolation, so "$a$b"
+				// is a variable followed by another one
+				for r := firstRune(lit[end:]); isIdentifierTail(r) && r != '$'; r = firstRune(lit[end:]) {
+					end += utf8.RuneLen(r)
+				}
+				parts = append(parts, stringPart{kind: partVar, text: lit[i+1 : end], start: i + 1, end: end + 1})
+				i = end - 1
+			default:
+				value.WriteByte('$')
+			}
+		default:
+			value.WriteByte(lit[i])
+		}
+	}
+	flush()
+	return parts, invalid
+}
+
+// escape decodes the escape sequence at the start of s (after the
+// backslash) and returns how many bytes it used
+func escape(s string) (rune, int, error) {
+	if s == "" {
+		return 0, 0, errors.New("escape sequence at the end of the string")
+	}
+	switch s[0] {
+	case 'n':
+		return '\n', 1, nil
+	case 't':
+		return '\t', 1, nil
+	case 'r':
+		return '\r', 1, nil
+	case '"', '\\', '$':
+		return rune(s[0]), 1, nil
+	case 'u':
+		if len(s) < 5 {
+			return 0, 0, errors.New("\\u requires four hex digits")
+		}
+		r, err := strconv.ParseUint(s[1:5], 16, 32)
+		if err != nil {
+			return 0, 0, errors.New("\\u requires four hex digits")
+		}
+		return rune(r), 5, nil
+	}
+	return 0, 0, errors.New("invalid escape sequence \\" + s[:1])
+}
+
+// skipBlock returns the offset after the '}' closing the block which
+// starts at s[start], strings inside the block are skipped as a whole
+func skipBlock(s string, start int) (int, bool) {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1, true
+			}
+		case '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return 0, false
+			}
+			i += end + 1
+		case '"':
+			end, ok := skipString(s, i)
+			if !ok {
+				return 0, false
+			}
+			i = end - 1
+		}
+	}
+	return 0, false
+}
+
+// skipString returns the offset after the '"' closing the
+// string which starts at s[start]
+func skipString(s string, start int) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, true
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				end, ok := skipBlock(s, i+1)
+				if !ok {
+					return 0, false
+				}
+				i = end - 1
+			}
+		}
+	}
+	return 0, false
+}
+
+// isIdentifier reports if s is a single IDENTIFIER token
+func isIdentifier(s string) bool {
+	if !isIdentifierStart(firstRune(s)) {
+		return false
+	}
+	for _, r := range s[utf8.RuneLen(firstRune(s)):] {
+		if !isIdentifierTail(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentifierStart(r rune) bool {
+	return unicode.IsLower(r) || unicode.IsUpper(r) || strings.ContainsRune("|!?.-+*&^%@~", r)
+}
+
+func isIdentifierTail(r rune) bool {
+	return isIdentifierStart(r) || unicode.Is(unicode.Nd, r) || r == '$' || r == '#'
+}
+
+// firstRune returns the first rune of s or utf8.RuneError
+// when s is empty
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrebq/gshell/internal/parser/legacy"
+)
+
+// legacyCases are parsed by both parsers, which must produce
+// the same trees, spans and errors at the same places. Unterminated strings with an
+// invalid escape sequence are left out, as the new parser names the
+// escape instead of reporting a token recognition error
+var legacyCases = []string{
+	"",
+	"{}",
+	"\n\n{\n\n}\n\n",
+	"{ echo hello world; echo ola mundo }",
+	"{ echo 123 1.5 -1 -1.5 1. 1a world123 w123h }",
+	"{ echo-1! should.be.valid |a ~b @c }",
+	"{ println $variable $a$b $-v $--name }",
+	"{ if true { println false; } else { println true; } }",
+	"{ echo [ 123  $abc identifier\n [ nested ] { a } ] }",
+	`{ echo "a\tb\n\"c\" \\ \u00e9" 'C:\raw "x"' "" }`,
+	`{ println "user $name has ${count} items ${ echo { a } "}" }" }`,
+	"{ echo \"multi\nline $x\" \"${\n a\n b $c\n}\" }",
+	"# leading\n{ # after\n\t# before\n\techo a # trailing\n\t#| block |# echo b; # after b\n\t# last\n} # end\n# eof",
+	"{ echo a; # one\n\techo b ; #| two |#\n}",
+	"{ echo #| in the middle |# a }",
+	"{ echo é ção 日本 }",
+	"{ echo \"日本 $語\" }",
+	// errors
+	"{\n\tprintln ok\n\t[ broken ]\n\tprintln fine\n\techo a ] ;\n\tset x 1\n}\n",
+	"echo hi",
+	"{ echo ` }",
+	"{ echo [ }",
+	"{ echo [ 1 2",
+	"{ echo { a",
+	"{ echo $ }",
+	"{ echo $1 }",
+	"{ a [ } b }",
+	"} { a }",
+	"{ a } } b",
+	"{ a } \n ]",
+	"{ a { ] b } c }",
+	"{\n\ta\n\t; b\n}",
+	"{ echo \"bad \\q\" after }",
+	"{ echo \"open",
+	"{ echo 'open",
+	"{ echo \"a\\u12",
+	"{ echo \"${ x",
+	"{ echo \"${ echo [ }\" }",
+	"{ #| open",
+	"{ a # c\n",
+	"{ a [ # c\n ` ] # d\n b }",
+	"{ a { b # c\n ] } # d\n c }",
+	"{ a `b` [ c ` }",
+}
+
+var legacyPrograms = []string{
+	"",
+	"echo a\necho b\n",
+	"# head\necho a # trailing\n\n# tail",
+	"echo a; echo b; }",
+	"} echo a",
+	"echo [ 1\n2 ]\nfunc f [] {\n\techo $x\n}\n",
+	"a [ }",
+	"a {",
+	"a ] b\nc",
+	"{ a }",
+}
+
+func TestParserMatchesLegacy(t *testing.T) {
+	codes := legacyCases
+	for _, tc := range allTests {
+		if !tc.nativeOnly {
+			codes = append(codes, tc.code)
+		}
+	}
+	for _, code := range codes {
+		tree, err := Parse(code)
+		oldTree, oldErr := legacy.Parse(code)
+		compareWithLegacy(t, code, tree, oldTree, err, oldErr)
+	}
+	for _, code := range legacyPrograms {
+		tree, err := ParseProgram("test.gsh", code)
+		oldTree, oldErr := legacy.ParseProgram("test.gsh", code)
+		compareWithLegacy(t, code, tree, oldTree, err, oldErr)
+	}
+}
+
+func compareWithLegacy(t *testing.T, code string, tree, oldTree interface{}, err, oldErr error) {
+	t.Helper()
+	if !reflect.DeepEqual(tree, oldTree) {
+		t.Errorf("Tree of %q should be\n%#v\ngot\n%#v", code, oldTree, tree)
+	}
+	if (err == nil) != (oldErr == nil) {
+		t.Errorf("Errors of %q should be %v got %v", code, oldErr, err)
+		return
+	}
+	if err == nil {
+		return
+	}
+	errs, oldErrs := err.(Errors), oldErr.(legacy.Errors)
+	if len(errs) != len(oldErrs) {
+		t.Errorf("Errors of %q should be\n%v\ngot\n%v", code, oldErr, err)
+		return
+	}
+	for i, e := range errs {
+		old := oldErrs[i]
+		// the tokens expected grow with the language, so
+		// only the place of each error is compared
+		if e.Span != old.Span || e.Offending != old.Offending {
+			t.Errorf("Error %v of %q should be %#v got %#v", i, code, old, e)
+		}
+	}
+}
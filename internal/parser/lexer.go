@@ -0,0 +1,414 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type (
+	tokenKind byte
+
+	token struct {
+		kind tokenKind
+		// text is the raw input that produced this token
+		text string
+
+		offset int
+		// line starts at 1 and column at 0, like in ANTLR
+		line   int
+		column int
+		// position right after the token
+		endLine   int
+		endColumn int
+
+		// msg explains why a tokError could not be recognized
+		msg string
+	}
+
+	// lexer splits the input into the tokens described by GShell.g4
+	lexer struct {
+		input  string
+		offset int
+		line   int
+		column int
+
+		// depth of the parenthesis inside a (= ...)
+		// expression, 0 outside of them
+		expression int
+	}
+)
+
+// the order of the kinds follows the token types in GShell.g4,
+// expected tokens are listed in this order
+const (
+	tokEOF tokenKind = iota
+	tokOpenBlock
+	tokCloseBlock
+	tokDollar
+	tokOpenList
+	tokOpenMap
+	tokCloseList
+	tokOpenParen
+	tokCloseParen
+	// the '(' of a (= ...) expression
+	tokOpenExpression
+	tokIdentifier
+	tokFlag
+	tokNumber
+	tokString
+	tokRawString
+	tokUnterminatedString
+	tokTerminator
+	tokNL
+	tokOperator
+	tokComment
+	tokUnterminatedComment
+	tokError
+)
+
+var tokenNames = [...]string{
+	tokEOF:                "<EOF>",
+	tokOpenBlock:          "'{'",
+	tokCloseBlock:         "'}'",
+	tokDollar:             "'$'",
+	tokOpenList:           "'['",
+	tokOpenMap:            "'%['",
+	tokCloseList:          "']'",
+	tokOpenParen:          "'('",
+	tokCloseParen:         "')'",
+	tokOpenExpression:     "'(='",
+	tokIdentifier:         "IDENTIFIER",
+	tokFlag:               "FLAG",
+	tokNumber:             "NUMBER",
+	tokString:             "STRING",
+	tokRawString:          "RAW_STRING",
+	tokUnterminatedString: "UNTERMINATED_STRING",
+	tokTerminator:         "TERMINATOR",
+	tokNL:                 "NL",
+	tokOperator:           "OPERATOR",
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{
+		input: input,
+		line:  1,
+	}
+}
+
+func (l *lexer) next() token {
+	if l.expression > 0 {
+		return l.nextExpression()
+	}
+	l.skipWhitespace()
+	start := l.mark()
+	if l.offset >= len(l.input) {
+		return l.emit(start, tokEOF)
+	}
+	r := l.peek()
+	switch {
+	case r == '\n':
+		l.advance()
+		return l.emit(start, tokNL)
+	case r == ';':
+		l.advance()
+		return l.emit(start, tokTerminator)
+	case r == '{':
+		l.advance()
+		return l.emit(start, tokOpenBlock)
+	case r == '}':
+		l.advance()
+		return l.emit(start, tokCloseBlock)
+	case r == '[':
+		l.advance()
+		return l.emit(start, tokOpenList)
+	case r == '%' && strings.HasPrefix(l.input[l.offset:], "%["):
+		l.advance()
+		l.advance()
+		return l.emit(start, tokOpenMap)
+	case r == ']':
+		l.advance()
+		return l.emit(start, tokCloseList)
+	case r == '(':
+		l.advance()
+		tok := l.emit(start, tokOpenParen)
+		if l.startsExpression() {
+			tok.kind = tokOpenExpression
+			l.expression = 1
+		}
+		return tok
+	case r == ')':
+		l.advance()
+		return l.emit(start, tokCloseParen)
+	case r == '$':
+		l.advance()
+		return l.emit(start, tokDollar)
+	case r == '#':
+		return l.comment(start)
+	case r == '"':
+		return l.quotedString(start)
+	case r == '\'':
+		return l.rawString(start)
+	case isDigit(r):
+		return l.number(start)
+	case isIdentifierStart(r):
+		// a '-' followed by digits is also an identifier,
+		// as IDENTIFIER comes before NUMBER in the grammar
+		for l.offset < len(l.input) && isIdentifierTail(l.peek()) {
+			l.advance()
+		}
+		text := l.input[start.offset:l.offset]
+		if !isFlag(text) {
+			return l.emit(start, tokIdentifier)
+		}
+		if strings.HasPrefix(text, "--") && l.peek() == '=' {
+			// the value is read as the next token
+			l.advance()
+		}
+		return l.emit(start, tokFlag)
+	}
+	l.advance()
+	tok := l.emit(start, tokError)
+	tok.msg = "token recognition error at: " + quoteToken(tok.text)
+	return tok
+}
+
+// startsExpression checks if the input after a '(' is
+// an expression, ie.: (= 1 + 2)
+func (l *lexer) startsExpression() bool {
+	rest := strings.TrimLeft(l.input[l.offset:], " \t\r")
+	return strings.HasPrefix(rest, "=")
+}
+
+// nextExpression returns the next token inside an expression,
+// where operators are tokens on their own instead of being
+// part of identifiers and line breaks are ignored
+func (l *lexer) nextExpression() token {
+	for l.offset < len(l.input) && unicode.IsSpace(l.peek()) {
+		l.advance()
+	}
+	start := l.mark()
+	if l.offset >= len(l.input) {
+		return l.emit(start, tokEOF)
+	}
+	r := l.peek()
+	switch {
+	case r == '(':
+		l.advance()
+		l.expression++
+		return l.emit(start, tokOpenParen)
+	case r == ')':
+		l.advance()
+		l.expression--
+		return l.emit(start, tokCloseParen)
+	case r == '$':
+		l.advance()
+		return l.emit(start, tokDollar)
+	case r == '"':
+		return l.quotedString(start)
+	case r == '\'':
+		return l.rawString(start)
+	case isDigit(r):
+		return l.number(start)
+	case isLetter(r):
+		for l.offset < len(l.input) && isExpressionName(l.peek()) {
+			l.advance()
+		}
+		return l.emit(start, tokIdentifier)
+	case strings.ContainsRune("=!<>", r):
+		l.advance()
+		if l.peek() == '=' {
+			l.advance()
+		}
+		return l.emit(start, tokOperator)
+	case strings.ContainsRune("+-*/%", r):
+		l.advance()
+		return l.emit(start, tokOperator)
+	case strings.ContainsRune("{}[];", r):
+		// the expression was not closed, the
+		// rest of the code is read as usual
+		l.expression = 0
+		return l.next()
+	}
+	l.advance()
+	tok := l.emit(start, tokError)
+	tok.msg = "token recognition error at: " + quoteToken(tok.text)
+	return tok
+}
+
+// comment reads a line comment up to (but not including) the
+// line break or a block comment (#| ... |#) which can span
+// multiple lines
+func (l *lexer) comment(start token) token {
+	if strings.HasPrefix(l.input[l.offset:], "#|") {
+		end := strings.Index(l.input[l.offset+2:], "|#")
+		if end < 0 {
+			l.skipTo(len(l.input))
+			return l.emit(start, tokUnterminatedComment)
+		}
+		l.skipTo(l.offset + 2 + end + 2)
+		return l.emit(start, tokComment)
+	}
+	for l.offset < len(l.input) && l.peek() != '\n' {
+		l.advance()
+	}
+	return l.emit(start, tokComment)
+}
+
+func (l *lexer) number(start token) token {
+	l.digits()
+	if l.peek() == '.' {
+		save := *l
+		l.advance()
+		if !isDigit(l.peek()) {
+			*l = save
+		} else {
+			l.digits()
+		}
+	}
+	return l.emit(start, tokNumber)
+}
+
+func (l *lexer) digits() {
+	for l.offset < len(l.input) && isDigit(l.peek()) {
+		l.advance()
+	}
+}
+
+// quotedString reads a double quoted string, its content is
+// decoded by the parser (see unquote). A string cut by the end of
+// the input is a tokUnterminatedString, unless it has an invalid
+// escape sequence as more input cannot fix it
+func (l *lexer) quotedString(start token) token {
+	if end, ok := quickString(l.input, l.offset); ok {
+		l.skipTo(end)
+		return l.emit(start, tokString)
+	}
+	m := newQuotedMatcher(l.input)
+	kind, end := m.token(l.offset)
+	l.skipTo(end)
+	if kind == tokError {
+		return l.fail(start, m.invalid(start.offset))
+	}
+	return l.emit(start, kind)
+}
+
+// rawString reads a single quoted string, its content
+// is taken verbatim
+func (l *lexer) rawString(start token) token {
+	l.advance()
+	end := strings.IndexByte(l.input[l.offset:], '\'')
+	if end < 0 {
+		l.skipTo(len(l.input))
+		return l.emit(start, tokUnterminatedString)
+	}
+	l.skipTo(l.offset + end + 1)
+	return l.emit(start, tokRawString)
+}
+
+func (l *lexer) fail(start token, msg string) token {
+	tok := l.emit(start, tokError)
+	tok.msg = msg
+	return tok
+}
+
+func (l *lexer) skipWhitespace() {
+	for l.offset < len(l.input) {
+		switch l.peek() {
+		case ' ', '\r', '\t':
+			l.advance()
+		default:
+			return
+		}
+	}
+}
+
+// skipTo advances up to the given offset
+func (l *lexer) skipTo(offset int) {
+	for l.offset < offset {
+		l.advance()
+	}
+}
+
+func (l *lexer) peek() rune {
+	if l.offset >= len(l.input) {
+		return utf8.RuneError
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.offset:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, sz := utf8.DecodeRuneInString(l.input[l.offset:])
+	l.offset += sz
+	if r == '\n' {
+		l.line++
+		l.column = 0
+	} else {
+		l.column++
+	}
+	return r
+}
+
+// mark returns an empty token at the current position
+func (l *lexer) mark() token {
+	return token{line: l.line, column: l.column, offset: l.offset}
+}
+
+func (l *lexer) emit(start token, kind tokenKind) token {
+	start.kind = kind
+	start.text = l.input[start.offset:l.offset]
+	start.endLine, start.endColumn = l.line, l.column
+	return start
+}
+
+// String returns the token as it is shown in error messages
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return quoteToken("<EOF>")
+	}
+	return quoteToken(t.text)
+}
+
+// hidden reports if the token is a comment, which is
+// attached to the nodes around it instead of parsed
+func (t token) hidden() bool {
+	switch t.kind {
+	case tokComment, tokUnterminatedComment:
+		return true
+	}
+	return false
+}
+
+func quoteToken(text string) string {
+	text = strings.Replace(text, "\n", `\n`, -1)
+	text = strings.Replace(text, "\t", `\t`, -1)
+	text = strings.Replace(text, "\r", `\r`, -1)
+	return "'" + text + "'"
+}
+
+// isFlag checks if an identifier is a flag, ie.: -v or --name
+func isFlag(s string) bool {
+	for i := 0; i < 2 && strings.HasPrefix(s, "-"); i++ {
+		s = s[1:]
+		if isLetter(firstRune(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLetter(r rune) bool {
+	return unicode.IsLower(r) || unicode.IsUpper(r)
+}
+
+// isExpressionName checks the runes which can be used in names
+// inside an expression, operators are not allowed so $a-1 is
+// read as $a - 1
+func isExpressionName(r rune) bool {
+	return isLetter(r) || isDigit(r) || r == '.' || r == '?'
+}
+
+func isDigit(r rune) bool {
+	return unicode.Is(unicode.Nd, r)
+}
@@ -0,0 +1,900 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// parser is a recursive descent parser for the grammar
+	// documented in GShell.g4.
+	//
+	// A syntax error inside a command drops the command and parsing
+	// continues after it, errors between commands skip the whole line,
+	// so every error in the code is reported along with the other commands
+	parser struct {
+		// every token of the input, comments included
+		tokens []token
+		// pos is the index of the current token and prev of the
+		// last one consumed, both skip the comments
+		pos, prev int
+		// comments before next were already attached to a node
+		next int
+
+		// errs are the syntax errors, built are the errors found in
+		// the tokens of the tree, the latter are dropped along with
+		// the commands that had a syntax error
+		errs  Errors
+		built Errors
+		// index of the last token reported, the same token might
+		// be rejected again while leaving the nested rules
+		lastError int
+
+		// spans refer to source and start at origin
+		source string
+		origin ast.Position
+	}
+
+	// tokenSet holds the kinds of token expected at some point
+	tokenSet uint32
+
+	// bailout abandons the command being parsed
+	bailout struct{}
+)
+
+var (
+	argumentStart = setOf(tokOpenBlock, tokDollar, tokOpenList, tokOpenMap, tokOpenParen, tokOpenExpression,
+		tokIdentifier, tokFlag, tokNumber, tokString, tokRawString, tokUnterminatedString)
+
+	// a command is a name followed by its arguments or an expression,
+	// flags are only special when used as arguments and $f calls
+	// the value of a variable
+	commandStart = setOf(tokDollar, tokIdentifier, tokFlag, tokOpenExpression)
+
+	beforeScript = setOf(tokOpenBlock, tokNL)
+	afterScript  = setOf(tokEOF, tokNL)
+
+	// between the commands and inside a command
+	blockItems    = commandStart | setOf(tokCloseBlock, tokNL)
+	blockCommand  = argumentStart | setOf(tokCloseBlock, tokTerminator, tokNL)
+	programItems  = commandStart | setOf(tokEOF, tokNL)
+	programSyntax = argumentStart | setOf(tokEOF, tokTerminator, tokNL)
+
+	listItems = argumentStart | setOf(tokCloseList, tokNL)
+
+	substitutionSyntax = argumentStart | setOf(tokCloseParen, tokNL)
+
+	// what might start an operand inside an expression
+	operandStart = []string{"'('", "'-'", "'not'", "'true'", "'false'", "'$'", "NUMBER", "STRING", "RAW_STRING"}
+)
+
+// Parse takes the code of a script block and returns its AST.
+//
+// Syntax errors are returned as Errors along with the tree of the
+// code around them, commands with errors are left out of the tree
+func Parse(code string) (*ast.Ast, error) {
+	return ParseSource("", code)
+}
+
+// ParseSource works like Parse but the spans of the nodes
+// refer to the given source (usually a file name)
+func ParseSource(source, code string) (*ast.Ast, error) {
+	return parse(source, code, ast.Position{Line: 1, Column: 1}, false)
+}
+
+// ParseProgram parses code written as a file, where the top-level
+// commands are not wrapped in a block. It produces the same kind of
+// tree as Parse, marked with Ast.IsProgram so it is formatted
+// without the braces
+func ParseProgram(source, code string) (*ast.Ast, error) {
+	return parse(source, code, ast.Position{Line: 1, Column: 1}, true)
+}
+
+// parse reads code which starts at origin, blocks interpolated
+// in strings are parsed on their own from the middle of a line.
+// When program is true the code is read with the program rule
+func parse(source, code string, origin ast.Position, program bool) (*ast.Ast, error) {
+	p := newParser(source, code, origin)
+	tree := ast.New()
+	if program {
+		tree.SetProgram(true)
+		p.parseProgram(tree)
+	} else {
+		p.parseStart(tree)
+	}
+
+	errs := append(p.errs, p.built...)
+	if len(errs) > 0 {
+		errs.sort()
+		return tree, errs
+	}
+	return tree, nil
+}
+
+func newParser(source, code string, origin ast.Position) *parser {
+	p := &parser{
+		prev:      -1,
+		lastError: -1,
+		source:    source,
+		origin:    origin,
+	}
+	lex := newLexer(code)
+	for {
+		tok := lex.next()
+		if tok.kind == tokError {
+			pos := p.position(tok.line, tok.column)
+			p.errs = append(p.errs, &SyntaxError{
+				Span: ast.Span{Source: source, Start: pos, End: pos},
+				Msg:  tok.msg,
+			})
+			continue
+		}
+		p.tokens = append(p.tokens, tok)
+		if tok.kind == tokEOF {
+			break
+		}
+	}
+	p.pos = p.visible(0)
+	return p
+}
+
+// parseStart reads: NL* '{' ... '}' NL* EOF
+func (p *parser) parseStart(tree *ast.Ast) {
+	tree.AddLeadingComment(p.takeComments(p.pos)...)
+	p.newLines(beforeScript)
+	sc, closed := p.parseBlock()
+	if closed {
+		p.newLines(afterScript)
+	}
+	// without a block nothing else can be parsed
+	for p.kind() != tokEOF {
+		p.advance()
+	}
+	tree.SetRoot(sc)
+	tree.AddTrailingComment(p.takeComments(p.pos)...)
+}
+
+// parseProgram reads: NL* commands... EOF
+func (p *parser) parseProgram(tree *ast.Ast) {
+	start := p.pos
+	sc := ast.NewScript()
+	p.newLines(programItems)
+	for commandStart.has(p.kind()) {
+		p.parseItem(sc, programItems, programSyntax)
+	}
+	sc.AddComment(p.takeComments(p.pos)...)
+	tree.SetRoot(sc.SetSpan(p.span(start, p.prev)))
+}
+
+// parseBlock reads: '{' NL* commands... '}'.
+//
+// It returns false if the block was not closed,
+// the error is reported before returning
+func (p *parser) parseBlock() (*ast.Script, bool) {
+	start := p.pos
+	sc := ast.NewScript()
+	if p.kind() != tokOpenBlock {
+		p.mismatch(setOf(tokOpenBlock))
+		sc.AddComment(p.takeComments(p.prev)...)
+		return sc.SetSpan(p.span(start, p.prev)), false
+	}
+	p.advance()
+	p.newLines(blockItems)
+	for commandStart.has(p.kind()) {
+		p.parseItem(sc, blockItems, blockCommand)
+	}
+	if p.kind() != tokCloseBlock {
+		p.mismatch(setOf(tokCloseBlock))
+		sc.AddComment(p.takeComments(p.prev)...)
+		return sc.SetSpan(p.span(start, p.prev)), false
+	}
+	end := p.advance()
+	sc.AddComment(p.takeComments(end)...)
+	return sc.SetSpan(p.span(start, end)), true
+}
+
+// parseItem reads a command followed by an optional terminator,
+// items are the tokens which might follow the terminator and
+// syntax the tokens which might appear after the command name
+func (p *parser) parseItem(sc *ast.Script, items, syntax tokenSet) {
+	p.parseCommand(sc, syntax)
+	// a dropped command might stop at a '}' closing nothing
+	p.sync(items | setOf(tokTerminator))
+	if k := p.kind(); k == tokTerminator || k == tokNL {
+		p.advance()
+		p.newLines(items)
+	}
+}
+
+// parseCommand reads a command and adds it to sc, unless it
+// had a syntax error
+func (p *parser) parseCommand(sc *ast.Script, syntax tokenSet) {
+	start, next, built := p.pos, p.next, len(p.built)
+	cmd := &ast.Cmd{}
+	cmd.AddLeadingComment(p.takeComments(start)...)
+	if !p.commandBody(cmd, syntax) {
+		// comments and errors found in the dropped command
+		// are left for the nodes after it
+		p.next, p.built = next, p.built[:built]
+		p.skip(false)
+		return
+	}
+	cmd.SetSpan(p.span(start, p.prev))
+
+	// comments up to the end of the line belong to the command
+	end := p.pos
+	if p.kind() == tokTerminator {
+		end = p.visible(end + 1)
+	}
+	cmd.AddTrailingComment(p.takeComments(end)...)
+	sc.AddCommand(cmd)
+}
+
+// commandBody parses cmd, it returns false
+// if the command had a syntax error
+func (p *parser) commandBody(cmd *ast.Cmd, syntax tokenSet) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, abandoned := r.(bailout); !abandoned {
+				panic(r)
+			}
+			ok = false
+		}
+	}()
+	p.parseCommandArgs(cmd, syntax)
+	return true
+}
+
+// parseCommandArgs reads the name and arguments of cmd,
+// a syntax error abandons the command
+func (p *parser) parseCommandArgs(cmd *ast.Cmd, syntax tokenSet) {
+	if p.kind() == tokOpenExpression {
+		// an expression used as a command takes no arguments
+		cmd.SetExpression(p.parseExpression())
+		p.expect(syntax &^ argumentStart)
+		return
+	}
+	if p.kind() == tokDollar {
+		// $f 1 2 calls the value of $f, which cannot be spread
+		v, _ := p.parseSingleArgument().(ast.Var)
+		cmd.SetCommandVar(v)
+	} else {
+		cmd.SetCommand(p.symbol(p.tokens[p.advance()]))
+	}
+	for {
+		p.expect(syntax)
+		if !argumentStart.has(p.kind()) {
+			return
+		}
+		start := p.pos
+		if p.kind() == tokFlag {
+			flag := p.parseFlag()
+			cmd.AddFlag(flag.WithSpan(p.span(start, p.prev)))
+			continue
+		}
+		arg := p.parseArgument()
+		cmd.AddArgumentAt(arg, p.span(start, p.prev))
+	}
+}
+
+// parseArgument reads any argument, including spreads which
+// are only allowed in the arguments of commands and in lists
+func (p *parser) parseArgument() ast.Argument {
+	tok := p.tokens[p.pos]
+	switch tok.kind {
+	case tokOpenBlock:
+		sc, closed := p.parseBlock()
+		if !closed {
+			panic(bailout{})
+		}
+		return p.notSpread(sc)
+	case tokOpenList:
+		return p.spread(p.parseList())
+	case tokOpenMap:
+		return p.notSpread(p.parseMap())
+	case tokOpenParen:
+		return p.spread(p.parseSubstitution())
+	case tokOpenExpression:
+		return p.notSpread(p.parseExpression())
+	case tokDollar:
+		start := p.advance()
+		// names of variables might look like flags: $-v
+		if k := p.kind(); k != tokIdentifier && k != tokFlag {
+			p.mismatch(setOf(tokIdentifier))
+			panic(bailout{})
+		}
+		name := p.tokens[p.advance()].text
+		span := p.span(start, p.prev)
+		// $args... is read as a single IDENTIFIER
+		spread := strings.HasSuffix(name, spreadSuffix) && len(name) > len(spreadSuffix)
+		if spread {
+			name = strings.TrimSuffix(name, spreadSuffix)
+			span.End.Column -= len(spreadSuffix)
+		}
+		v, err := ast.NewVarString(name)
+		if err != nil {
+			p.fail(tok, fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", "$"+name, err))
+			v, _ = ast.NewVarString("error-invalid-variable")
+		}
+		if spread {
+			return ast.NewSpread(v.WithSpan(span))
+		}
+		return v.WithSpan(span)
+	case tokNumber:
+		p.advance()
+		number, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return ast.NewText(tok.text)
+		}
+		return ast.NewNumber(number)
+	case tokString, tokRawString, tokUnterminatedString:
+		p.advance()
+		return p.notSpread(p.text(tok))
+	}
+	p.advance()
+	return p.symbol(tok)
+}
+
+// parseSingleArgument works like parseArgument but does not
+// accept spreads
+func (p *parser) parseSingleArgument() ast.Argument {
+	tok := p.tokens[p.pos]
+	arg := p.parseArgument()
+	if _, isSpread := arg.(*ast.Spread); isSpread {
+		p.fail(tok, fmt.Sprintf("%v cannot be spread here", arg))
+	}
+	return arg
+}
+
+// spread wraps arg in an ast.Spread if it is
+// immediately followed by '...'
+func (p *parser) spread(arg ast.Argument) ast.Argument {
+	if !p.atSpread() {
+		return arg
+	}
+	p.advance()
+	return ast.NewSpread(arg)
+}
+
+// notSpread fails if arg, which cannot be spread, is
+// immediately followed by '...'
+func (p *parser) notSpread(arg ast.Argument) ast.Argument {
+	if p.atSpread() {
+		p.fail(p.tokens[p.pos], fmt.Sprintf("%v cannot be spread, only variables, lists and substitutions can", arg))
+	}
+	return arg
+}
+
+// atSpread returns true if the current token is
+// a '...' glued to the previous one
+func (p *parser) atSpread() bool {
+	tok, prev := p.tokens[p.pos], p.tokens[p.prev]
+	return tok.kind == tokIdentifier && tok.text == spreadSuffix &&
+		tok.offset == prev.offset+len(prev.text)
+}
+
+// spreadSuffix marks arguments whose items are passed
+// as separate arguments
+const spreadSuffix = "..."
+
+// parseFlag reads a -short or a --long flag, long flags written
+// as --name=value take the next argument as their value. Flags used
+// anywhere else are read as symbols by parseArgument
+func (p *parser) parseFlag() ast.Flag {
+	tok := p.tokens[p.advance()]
+	name := tok
+	name.text = strings.TrimSuffix(strings.TrimLeft(tok.text, "-"), "=")
+	switch {
+	case !strings.HasPrefix(tok.text, "--"):
+		return ast.NewShortFlag(p.symbol(name))
+	case !strings.HasSuffix(tok.text, "="):
+		return ast.NewLongFlag(p.symbol(name), nil)
+	}
+	if !argumentStart.has(p.kind()) {
+		p.mismatch(argumentStart)
+		panic(bailout{})
+	}
+	return ast.NewLongFlag(p.symbol(name), p.parseSingleArgument())
+}
+
+// parseList reads: '[' (argument | NL)* ']'
+func (p *parser) parseList() ast.Argument {
+	start := p.advance()
+	var items []ast.Argument
+	var spans []ast.Span
+	for {
+		p.expect(listItems)
+		if p.kind() == tokNL {
+			p.advance()
+			continue
+		}
+		if !argumentStart.has(p.kind()) {
+			break
+		}
+		itemStart := p.pos
+		items = append(items, p.parseArgument())
+		spans = append(spans, p.span(itemStart, p.prev))
+	}
+	if p.kind() != tokCloseList {
+		p.mismatch(setOf(tokCloseList))
+		panic(bailout{})
+	}
+	span := p.span(start, p.advance())
+	return ast.NilList().Append(items...).WithSpan(span).WithItemSpans(spans)
+}
+
+// parseMap reads: '%[' NL* (key NL* value NL*)* ']', keys must
+// be symbols, numbers, text or variables and cannot be repeated
+func (p *parser) parseMap() ast.Argument {
+	start := p.advance()
+	m := ast.NilMap()
+	for {
+		p.expect(listItems)
+		if p.kind() == tokNL {
+			p.advance()
+			continue
+		}
+		if !argumentStart.has(p.kind()) {
+			break
+		}
+		keyTok := p.tokens[p.pos]
+		key := p.parseSingleArgument()
+		switch key.(type) {
+		case ast.Symbol, ast.Number, ast.Text, ast.Var:
+		default:
+			p.fail(keyTok, fmt.Sprintf("map keys must be symbols, numbers, text or variables got %v", key))
+		}
+		if _, found := m.Get(key); found {
+			p.fail(keyTok, fmt.Sprintf("duplicated map key %v", key))
+		}
+		p.skipNewLines()
+		if !argumentStart.has(p.kind()) {
+			p.mismatch(argumentStart | setOf(tokNL))
+			panic(bailout{})
+		}
+		m = m.Set(key, p.parseSingleArgument())
+	}
+	if p.kind() != tokCloseList {
+		p.mismatch(setOf(tokCloseList))
+		panic(bailout{})
+	}
+	return m.WithSpan(p.span(start, p.advance()))
+}
+
+// parseSubstitution reads: '(' NL* command NL* ')'
+func (p *parser) parseSubstitution() ast.Argument {
+	start := p.advance()
+	p.skipNewLines()
+	if !commandStart.has(p.kind()) {
+		p.mismatch(commandStart | setOf(tokNL))
+		panic(bailout{})
+	}
+	cmdStart := p.pos
+	cmd := &ast.Cmd{}
+	p.parseCommandArgs(cmd, substitutionSyntax)
+	cmd.SetSpan(p.span(cmdStart, p.prev))
+	p.skipNewLines()
+	if p.kind() != tokCloseParen {
+		p.mismatch(setOf(tokCloseParen, tokNL))
+		panic(bailout{})
+	}
+	return ast.NewSubstitution(cmd).SetSpan(p.span(start, p.advance()))
+}
+
+// parseExpression reads: '(' '=' expression ')', the
+// lexer reads the tokens up to the closing ')' as an expression
+func (p *parser) parseExpression() *ast.Expression {
+	start := p.advance()
+	if !p.isOperator("=") {
+		p.unexpected("'='")
+		panic(bailout{})
+	}
+	p.advance()
+	value := p.parseOr()
+	p.closeParen()
+	return ast.NewExpression(value).SetSpan(p.span(start, p.prev))
+}
+
+func (p *parser) parseOr() ast.Argument {
+	left := p.parseAnd()
+	for p.isKeyword("or") {
+		p.advance()
+		left = ast.NewBinary(ast.OpOr, left, p.parseAnd())
+	}
+	return left
+}
+
+func (p *parser) parseAnd() ast.Argument {
+	left := p.parseNot()
+	for p.isKeyword("and") {
+		p.advance()
+		left = ast.NewBinary(ast.OpAnd, left, p.parseNot())
+	}
+	return left
+}
+
+func (p *parser) parseNot() ast.Argument {
+	if p.isKeyword("not") {
+		p.advance()
+		return ast.NewUnary(ast.OpNot, p.parseNot())
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() ast.Argument {
+	left := p.parseAdditive()
+	for p.isOperator(ast.OpEq, ast.OpNe, ast.OpLt, ast.OpLe, ast.OpGt, ast.OpGe) {
+		op := ast.Operator(p.tokens[p.advance()].text)
+		left = ast.NewBinary(op, left, p.parseAdditive())
+	}
+	return left
+}
+
+func (p *parser) parseAdditive() ast.Argument {
+	left := p.parseMultiplicative()
+	for p.isOperator(ast.OpAdd, ast.OpSub) {
+		op := ast.Operator(p.tokens[p.advance()].text)
+		left = ast.NewBinary(op, left, p.parseMultiplicative())
+	}
+	return left
+}
+
+func (p *parser) parseMultiplicative() ast.Argument {
+	left := p.parseUnary()
+	for p.isOperator(ast.OpMul, ast.OpDiv, ast.OpMod) {
+		op := ast.Operator(p.tokens[p.advance()].text)
+		left = ast.NewBinary(op, left, p.parseUnary())
+	}
+	return left
+}
+
+func (p *parser) parseUnary() ast.Argument {
+	if p.isOperator(ast.OpSub) {
+		p.advance()
+		return ast.NewUnary(ast.OpNeg, p.parseUnary())
+	}
+	return p.parseOperand()
+}
+
+// parseOperand reads: '(' expression ')' | 'true' | 'false' | '$' IDENTIFIER
+// | NUMBER | STRING | RAW_STRING
+func (p *parser) parseOperand() ast.Argument {
+	switch p.kind() {
+	case tokOpenParen:
+		p.advance()
+		value := p.parseOr()
+		p.closeParen()
+		return value
+	case tokIdentifier:
+		if p.isKeyword("true") || p.isKeyword("false") {
+			return p.symbol(p.tokens[p.advance()])
+		}
+	case tokDollar, tokNumber, tokString, tokRawString, tokUnterminatedString:
+		return p.parseSingleArgument()
+	}
+	p.unexpected(operandStart...)
+	panic(bailout{})
+}
+
+// closeParen consumes the ')' after an expression
+func (p *parser) closeParen() {
+	if p.kind() != tokCloseParen {
+		p.unexpected(tokenNames[tokOperator], tokenNames[tokCloseParen])
+		panic(bailout{})
+	}
+	p.advance()
+}
+
+func (p *parser) isKeyword(text string) bool {
+	tok := p.tokens[p.pos]
+	return tok.kind == tokIdentifier && tok.text == text
+}
+
+func (p *parser) isOperator(ops ...ast.Operator) bool {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokOperator {
+		return false
+	}
+	for _, op := range ops {
+		if tok.text == string(op) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) text(tok token) ast.Argument {
+	switch tok.kind {
+	case tokRawString:
+		return ast.NewText(tok.text[1 : len(tok.text)-1])
+	case tokUnterminatedString:
+		p.fail(tok, "unterminated string").atEOF = true
+		return ast.NewText(tok.text[1:])
+	}
+	parts, err := unquote(tok.text)
+	if err != nil {
+		p.fail(tok, err.Error())
+	}
+	switch {
+	case len(parts) == 0:
+		return ast.NewText("")
+	case len(parts) == 1 && parts[0].kind == partText:
+		return ast.NewText(parts[0].text)
+	}
+	return p.template(tok, parts).SetSpan(p.tokenSpan(tok))
+}
+
+// template converts the parts of an interpolated string, blocks
+// are parsed on their own as they are kept inside the string token
+func (p *parser) template(tok token, parts []stringPart) *ast.Template {
+	tmpl := ast.NewTemplate()
+	for _, part := range parts {
+		switch part.kind {
+		case partText:
+			tmpl.AddPart(ast.NewText(part.text))
+		case partVar:
+			v, err := ast.NewVarString(part.text)
+			if err != nil {
+				p.fail(tok, fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", part.text, err))
+			}
+			tmpl.AddPart(v.WithSpan(ast.Span{
+				Source: p.source,
+				Start:  p.offsetIn(tok, part.start),
+				End:    p.offsetIn(tok, part.end),
+			}))
+		case partBlock:
+			// the block starts after the '$'
+			block, err := parse(p.source, part.text, p.offsetIn(tok, part.start+1), false)
+			if errs, ok := err.(Errors); ok {
+				for _, e := range errs {
+					// the string was closed, so more input cannot fix the block
+					e.atEOF = false
+					p.built = append(p.built, e)
+				}
+			}
+			tmpl.AddPart(block.Root())
+		}
+	}
+	return tmpl
+}
+
+func (p *parser) symbol(tok token) ast.Symbol {
+	s, err := ast.NewSymbol(tok.text)
+	if err != nil {
+		p.fail(tok, fmt.Sprintf("string %q could not be cast to ast.Symbol. cause: %v", tok.text, err))
+		s, _ = ast.NewSymbol("error-invalid-symbol")
+	}
+	return s
+}
+
+// newLines skips the empty lines before a command, reporting
+// the lines which cannot start one
+func (p *parser) newLines(expected tokenSet) {
+	for p.sync(expected); p.kind() == tokNL; p.sync(expected) {
+		p.advance()
+	}
+}
+
+// skipNewLines skips the line breaks allowed inside an argument
+func (p *parser) skipNewLines() {
+	for p.kind() == tokNL {
+		p.advance()
+	}
+}
+
+// sync skips the lines which start with an unexpected token, a '}'
+// which does not close a block is skipped on its own
+func (p *parser) sync(expected tokenSet) {
+	for k := p.kind(); k != tokEOF && !expected.has(k); k = p.kind() {
+		p.report("extraneous input %v expecting %v", expected.names())
+		if k == tokCloseBlock {
+			p.advance()
+			continue
+		}
+		p.skip(true)
+	}
+}
+
+// expect abandons the command when the current token cannot
+// continue it, the end of the input is left for the enclosing block
+func (p *parser) expect(expected tokenSet) {
+	if k := p.kind(); k != tokEOF && !expected.has(k) {
+		p.mismatch(expected)
+		panic(bailout{})
+	}
+}
+
+func (p *parser) mismatch(expected tokenSet) {
+	p.unexpected(expected.names()...)
+}
+
+// unexpected reports the current token, expected
+// are the names of what could be used instead
+func (p *parser) unexpected(expected ...string) {
+	p.report("mismatched input %v expecting %v", expected)
+}
+
+func (p *parser) report(format string, expected []string) {
+	if p.pos == p.lastError {
+		return
+	}
+	p.lastError = p.pos
+	tok := p.tokens[p.pos]
+	err := &SyntaxError{
+		Span:      p.tokenSpan(tok),
+		Offending: tok.text,
+		Expected:  expected,
+		Msg:       fmt.Sprintf(format, tok, joinNames(expected)),
+	}
+	if tok.kind == tokEOF {
+		err.Offending = "<EOF>"
+		err.atEOF = true
+	}
+	p.errs = append(p.errs, err)
+}
+
+// skip consumes the tokens up to the end of the command, blocks,
+// lists and substitutions are skipped as a whole. The '}' closing the
+// current block is kept, the terminator is consumed only if consumeEnd
+// is true
+func (p *parser) skip(consumeEnd bool) {
+	depth := 0
+	for {
+		switch p.kind() {
+		case tokEOF:
+			return
+		case tokOpenBlock, tokOpenList, tokOpenMap, tokOpenParen, tokOpenExpression:
+			depth++
+		case tokCloseBlock:
+			if depth == 0 {
+				return
+			}
+			depth--
+		case tokCloseList, tokCloseParen:
+			if depth > 0 {
+				depth--
+			}
+		case tokTerminator, tokNL:
+			if depth == 0 {
+				if consumeEnd {
+					p.advance()
+				}
+				return
+			}
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) kind() tokenKind {
+	return p.tokens[p.pos].kind
+}
+
+// advance consumes the current token and returns its index,
+// the end of the input is never consumed
+func (p *parser) advance() int {
+	current := p.pos
+	if p.tokens[current].kind != tokEOF {
+		p.prev = current
+		p.pos = p.visible(current + 1)
+	}
+	return current
+}
+
+// visible returns the index of the first token from i
+// which is not a comment
+func (p *parser) visible(i int) int {
+	for p.tokens[i].hidden() {
+		i++
+	}
+	return i
+}
+
+// takeComments returns the comments found before the token
+// at index upto which were not attached to another node
+func (p *parser) takeComments(upto int) []ast.Comment {
+	var comments []ast.Comment
+	for ; p.next < upto; p.next++ {
+		tok := p.tokens[p.next]
+		if tok.kind == tokUnterminatedComment {
+			p.fail(tok, "unterminated block comment").atEOF = true
+		}
+		if tok.hidden() {
+			comments = append(comments, ast.NewComment(tok.text))
+		}
+	}
+	return comments
+}
+
+// span returns the region of the code from the token at start
+// up to the one at stop, rules with errors might end before they start
+func (p *parser) span(start, stop int) ast.Span {
+	first := p.tokens[start]
+	pos := p.position(first.line, first.column)
+	span := ast.Span{Source: p.source, Start: pos, End: pos}
+	if stop >= start {
+		last := p.tokens[stop]
+		span.End = p.position(last.endLine, last.endColumn)
+	}
+	return span
+}
+
+func (p *parser) tokenSpan(tok token) ast.Span {
+	return ast.Span{
+		Source: p.source,
+		Start:  p.position(tok.line, tok.column),
+		End:    p.position(tok.endLine, tok.endColumn),
+	}
+}
+
+// fail records an error found while building the node of tok
+func (p *parser) fail(tok token, msg string) *SyntaxError {
+	err := &SyntaxError{
+		Span:      p.tokenSpan(tok),
+		Offending: tok.text,
+		Msg:       msg,
+	}
+	p.built = append(p.built, err)
+	return err
+}
+
+// offsetIn returns the position of the byte at offset inside
+// the text of tok
+func (p *parser) offsetIn(tok token, offset int) ast.Position {
+	line, column := tok.line, tok.column
+	text := tok.text[:offset]
+	if nl := strings.LastIndexByte(text, '\n'); nl >= 0 {
+		line += strings.Count(text, "\n")
+		column = 0
+		text = text[nl+1:]
+	}
+	return p.position(line, column+utf8.RuneCountInString(text))
+}
+
+// position converts a line (from 1) and column (from 0) of the
+// code to a position in the source, columns count runes from 1
+func (p *parser) position(line, column int) ast.Position {
+	if line == 1 {
+		column += p.origin.Column - 1
+	}
+	return ast.Position{Line: line + p.origin.Line - 1, Column: column + 1}
+}
+
+func setOf(kinds ...tokenKind) tokenSet {
+	var s tokenSet
+	for _, k := range kinds {
+		s |= 1 << k
+	}
+	return s
+}
+
+func (s tokenSet) has(k tokenKind) bool {
+	return s&(1<<k) != 0
+}
+
+// names returns the names of the tokens in the
+// order they are declared in the grammar
+func (s tokenSet) names() []string {
+	var names []string
+	for k := tokEOF; k <= tokOperator; k++ {
+		if s.has(k) {
+			names = append(names, tokenNames[k])
+		}
+	}
+	return names
+}
+
+func (s tokenSet) String() string {
+	return joinNames(s.names())
+}
+
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
@@ -3,6 +3,9 @@ package parser
 import (
 	"reflect"
 	"testing"
+	"unsafe"
+
+	"github.com/andrebq/gshell/ast"
 )
 
 type (
@@ -11,6 +14,8 @@ type (
 		code          string
 		fmt           string
 		expectedError error
+		// nativeOnly cases use syntax unknown to the legacy parser
+		nativeOnly bool
 	}
 )
 
@@ -39,6 +44,72 @@ var (
 		{subject: "can parse lists as argument",
 			code: `{ echo [ 123  $abc identifier ] }`,
 			fmt:  `{ echo [ 123 $abc identifier ] }`},
+		{subject: "can parse quoted strings",
+			code: `{ println "hello, world!" }`,
+			fmt:  `{ println "hello, world!" }`},
+		{subject: "quoted strings decode escape sequences",
+			code: `{ echo "a\tb\n\"c\" \\ \u00e9" }`,
+			fmt:  `{ echo "a\tb\n\"c\" \\ é" }`},
+		{subject: "raw strings are taken verbatim",
+			code: `{ echo 'C:\some path\ "quoted"' }`,
+			fmt:  `{ echo "C:\\some path\\ \"quoted\"" }`},
+		{subject: "strings can be used inside lists",
+			code: `{ echo [ "" 'a b' ] }`,
+			fmt:  `{ echo [ "" "a b" ] }`},
+		{subject: "text that looks like a symbol is still quoted",
+			code: `{ echo "hello" '123' }`,
+			fmt:  `{ echo "hello" "123" }`},
+		{subject: "strings interpolate variables",
+			code: `{ println "user $name has ${count} items" }`,
+			fmt:  `{ println "user $name has $count items" }`},
+		{subject: "braces are kept when the variable is followed by a symbol",
+			code: `{ println "${name}s cost \$5" 'raw $name' }`,
+			fmt:  `{ println "${name}s cost \$5" "raw \$name" }`},
+		{subject: "a '$' ends the name of an interpolated variable",
+			code: `{ println "${n}${ true } $a$b ${a}$" }`,
+			fmt:  `{ println "$n${ true } $a$b $a\$" }`},
+		{subject: "strings interpolate command blocks",
+			code: `{ println "total: ${ sum [1 2] } ${ echo "nested $a" }" }`,
+			fmt:  `{ println "total: ${ sum [ 1 2 ] } ${ echo "nested $a" }" }`},
+		{subject: "comments are kept in place",
+			code: "# header\n{\n# leading\necho a   # trailing\necho b; #| block |#\n\n  # dangling\n}\n# footer",
+			fmt:  "# header\n{\n\t# leading\n\techo a # trailing\n\techo b #| block |#\n\t# dangling\n}\n# footer"},
+		{subject: "comments force one command per line",
+			code: "{ echo a # note\n}",
+			fmt:  "{\n\techo a # note\n}"},
+		{subject: "comments are attached to nested blocks",
+			code: "{ if x { # inner\n a } }",
+			fmt:  "{ if x {\n\t# inner\n\ta\n} }"},
+		{subject: "block comments can span lines and # can be used inside symbols",
+			code: "{ echo a#b #| multi\nline |# }",
+			fmt:  "{\n\techo a#b #| multi\nline |#\n}"},
+		{subject: "commands can be substituted by their value",
+			code: "{ println (len $items) [ (true) ] (\n\tsum 1 2\n) }",
+			fmt:  "{ println (len $items) [ (true) ] (sum 1 2) }", nativeOnly: true},
+		{subject: "expressions follow the precedence of the operators",
+			code: "{ echo (= $a*2+1) (=(1 + 2) * -$b % 3) (= not $a == 1 or $b and (1 < 2 or false)) }",
+			fmt:  "{ echo (= $a * 2 + 1) (= (1 + 2) * -$b % 3) (= not $a == 1 or $b and (1 < 2 or false)) }", nativeOnly: true},
+		{subject: "operators are left associative",
+			code: `{ echo (= 1 - (2 - 3) - 4) (= "a" != 'b') "${ echo (= 1 / 2) }" }`,
+			fmt:  `{ echo (= 1 - (2 - 3) - 4) (= "a" != "b") "${ echo (= 1 / 2) }" }`, nativeOnly: true},
+		{subject: "expressions can be used as commands",
+			code: "{ guard { (= $a > 1) } { (=\n\t-$a\n) }; (= 1 + 2) }",
+			fmt:  "{\n\tguard { (= $a > 1) } { (= -$a) }\n\t(= 1 + 2)\n}", nativeOnly: true},
+		{subject: "maps are printed with sorted keys",
+			code: "{ echo %[ port 8080 name \"x\"\n\t1 [ a ] $k %[] \"b\" { echo } ] %foo }",
+			fmt:  `{ echo %[ 1 [ a ] "b" { echo } name "x" port 8080 $k %[ ] ] %foo }`, nativeOnly: true},
+		{subject: "flags keep their position",
+			code: "{ fetch -v --timeout=5 url --retry --dry-run\n-x [ -v ] --name= \"a b\" }",
+			fmt:  "{\n\tfetch -v --timeout=5 url --retry --dry-run\n\t-x [ -v ] --name=\"a b\"\n}", nativeOnly: true},
+		{subject: "lists can be spread",
+			code: "{ sum $args... [ 1 $b... ]... (range 3)... [ 1 ] ... $c }",
+			fmt:  "{ sum $args... [ 1 $b... ]... (range 3)... [ 1 ] ... $c }", nativeOnly: true},
+		{subject: "variables can be called",
+			code: "{ let $f (fn [$x] { $g $x }); $f 1 }",
+			fmt:  "{\n\tlet $f (fn [ $x ] { $g $x })\n\t$f 1\n}", nativeOnly: true},
+		{subject: "functions can be qualified by their module",
+			code: "{ module strings { export upper }; strings/upper $s \"$dir/name\" }",
+			fmt:  "{\n\tmodule strings { export upper }\n\tstrings/upper $s \"$dir/name\"\n}", nativeOnly: true},
 	}
 )
 
@@ -58,7 +129,7 @@ func TestParser(t *testing.T) {
 		if err != tc.expectedError {
 			t.Errorf("Case: %v Formatted error caused a different error: %v", tc.subject, err)
 		}
-		if !reflect.DeepEqual(ast, secondAst) {
+		if !equalIgnoringSpans(ast, secondAst) {
 			t.Errorf("Case: %v Generated AST's do not match", tc.subject)
 		}
 	}
@@ -67,3 +138,354 @@ func TestParser(t *testing.T) {
 		runTestCase(t, tc)
 	}
 }
+
+func TestTemplateVariables(t *testing.T) {
+	tree, err := Parse(`{ echo "$a$b" }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := tree.Root().Commands()[0].Arguments()[0].(*ast.Template)
+	expected := []ast.Argument{ast.NewVar(ast.MustNewSymbol("a")), ast.NewVar(ast.MustNewSymbol("b"))}
+	if parts := tmpl.Parts(); !equalIgnoringSpans(parts, expected) {
+		t.Errorf("Expecting %v got %v", expected, parts)
+	}
+}
+
+func TestParserErrors(t *testing.T) {
+	for _, code := range []string{
+		`{ echo "unterminated }`,
+		`{ echo 'unterminated }`,
+		`{ echo "invalid \q escape" }`,
+		`{ echo "short \u12" }`,
+		`{ echo "unterminated ${ block" }`,
+		`{ echo "invalid ${ [ } block" }`,
+		`{ echo #| unterminated }`,
+		`{ echo } #| unterminated`,
+		`{ echo () }`,
+		`{ echo (a b; c) }`,
+		`{ echo (= 1 +) }`,
+		`{ echo (= 1 2) }`,
+		`{ echo (= (1 + 2) }`,
+		`{ echo (= $a = 1) }`,
+		`{ (= 1 + 2) extra }`,
+		`{ echo %[ a ] }`,
+		`{ echo %[ a 1 a 2 ] }`,
+		`{ echo %[ $a 1 $a 2 ] }`,
+		`{ echo %[ [ a ] 1 ] }`,
+		`{ echo --name= }`,
+		`{ echo --name=; b }`,
+		`{ echo %[ a $b... ] }`,
+		`{ echo --flag=[ a ]... }`,
+		`{ echo (= $a... + 1) }`,
+		`{ echo (= 1 + 2)... }`,
+		`{ echo %[ a 1 ]... }`,
+		`{ echo { a }... }`,
+		`{ echo "a"... }`,
+		`{ echo "a $b"... }`,
+		`{ $args... 1 }`,
+	} {
+		if _, err := Parse(code); err == nil {
+			t.Errorf("Code %q should not be accepted", code)
+		}
+	}
+}
+
+func TestSpans(t *testing.T) {
+	tree, err := ParseSource("test.gsh", "{\n\techo [ 1 2 ]\n\tprintln   $name \"a $b\"\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := func(line, column int) ast.Position {
+		return ast.Position{Line: line, Column: column}
+	}
+	span := func(start, end ast.Position) ast.Span {
+		return ast.Span{Source: "test.gsh", Start: start, End: end}
+	}
+	cmds := tree.Root().Commands()
+	list := cmds[0].Arguments()[0].(*ast.List)
+	variable := cmds[1].Arguments()[0].(ast.Var)
+	tmpl := cmds[1].Arguments()[1].(*ast.Template)
+	for _, c := range []struct {
+		subject  string
+		expected ast.Span
+		actual   ast.Span
+	}{
+		{"root", span(pos(1, 1), pos(4, 2)), tree.Root().Span()},
+		{"first command", span(pos(2, 2), pos(2, 14)), cmds[0].Span()},
+		{"list", span(pos(2, 7), pos(2, 14)), list.Span()},
+		{"list argument", span(pos(2, 7), pos(2, 14)), cmds[0].ArgumentSpan(0)},
+		{"list item", span(pos(2, 11), pos(2, 12)), list.ItemSpan(1)},
+		{"second command", span(pos(3, 2), pos(3, 24)), cmds[1].Span()},
+		{"variable argument", span(pos(3, 12), pos(3, 17)), cmds[1].ArgumentSpan(0)},
+		{"variable", span(pos(3, 12), pos(3, 17)), variable.Span()},
+		{"template", span(pos(3, 18), pos(3, 24)), cmds[1].ArgumentSpan(1)},
+		{"template variable", span(pos(3, 21), pos(3, 23)), tmpl.Parts()[1].(ast.Var).Span()},
+	} {
+		if c.expected != c.actual {
+			t.Errorf("Span of %v should be %#v got %#v", c.subject, c.expected, c.actual)
+		}
+	}
+}
+
+func TestSpansInsideStrings(t *testing.T) {
+	tree, err := ParseSource("test.gsh", "{ echo \"a ${ b c }\" }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := tree.Root().Commands()[0].Arguments()[0].(*ast.Template)
+	cmd := tmpl.Parts()[1].(*ast.Script).Commands()[0]
+	expected := ast.Span{Source: "test.gsh", Start: ast.Position{Line: 1, Column: 14}, End: ast.Position{Line: 1, Column: 17}}
+	if cmd.Span() != expected {
+		t.Errorf("Span of the interpolated command should be %#v got %#v", expected, cmd.Span())
+	}
+}
+
+func TestFlags(t *testing.T) {
+	tree, err := Parse("{ fetch -v --timeout=5 url --dry-run 1 }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := tree.Root().Commands()[0]
+	expected := []ast.Flag{
+		ast.NewShortFlag(ast.MustNewSymbol("v")),
+		ast.NewLongFlag(ast.MustNewSymbol("timeout"), ast.NewNumber(5)),
+		ast.NewLongFlag(ast.MustNewSymbol("dry-run"), nil),
+	}
+	if !equalIgnoringSpans(cmd.Flags(), expected) {
+		t.Errorf("Flags should be %v got %v", expected, cmd.Flags())
+	}
+	// a flag without '=' never takes the next argument as its value
+	if args := cmd.Arguments(); len(args) != 2 || args[0] != ast.MustNewSymbol("url") || args[1] != ast.NewNumber(1) {
+		t.Errorf("Flags should not be listed as arguments got %v", args)
+	}
+	if span := cmd.Flags()[1].Span(); span.Start.Column != 12 || span.End.Column != 23 {
+		t.Errorf("Unexpected span for --timeout=5 %v", span)
+	}
+}
+
+var (
+	spanType = reflect.TypeOf(ast.Span{})
+	mapType  = reflect.TypeOf(&ast.Map{})
+)
+
+// equalIgnoringSpans works like reflect.DeepEqual but skips
+// the positions recorded by the parser, which are expected to
+// change when the code is formatted
+func equalIgnoringSpans(a, b interface{}) bool {
+	return equalValues(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+func equalValues(a, b reflect.Value) bool {
+	if a.IsValid() != b.IsValid() {
+		return false
+	}
+	if !a.IsValid() {
+		return true
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		if a.Type() == mapType {
+			// the layout of a map depends on the order of its keys,
+			// the maps might be found in unexported fields
+			return equalMaps((*ast.Map)(unsafe.Pointer(a.Pointer())), (*ast.Map)(unsafe.Pointer(b.Pointer())))
+		}
+		return equalValues(a.Elem(), b.Elem())
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			ft := a.Type().Field(i).Type
+			if ft == spanType || (ft.Kind() == reflect.Slice && ft.Elem() == spanType) {
+				continue
+			}
+			if !equalValues(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !equalValues(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.String:
+		return a.String() == b.String()
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	}
+	panic("equalIgnoringSpans: unsupported kind " + a.Kind().String())
+}
+
+func equalMaps(a, b *ast.Map) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	equal := true
+	a.ForEach(func(k, v ast.Argument) bool {
+		other, found := b.Get(k)
+		equal = found && equalIgnoringSpans(v, other)
+		return equal
+	})
+	return equal
+}
+
+func TestParserReportsAllErrors(t *testing.T) {
+	code := "{\n\tprintln ok\n\t[ oops ]\n\tprintln fine; ;\n\techo $ 1\n\tprintln last"
+	tree, err := Parse(code)
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Parse should return Errors got %#v", err)
+	}
+	betweenCommands := []string{"'}'", "'$'", "'(='", "IDENTIFIER", "FLAG", "NL"}
+	expected := []struct {
+		line, column int
+		offending    string
+		expected     []string
+	}{
+		{3, 2, "[", betweenCommands},
+		{4, 16, ";", betweenCommands},
+		{5, 9, "1", []string{"IDENTIFIER"}},
+		{6, 14, "<EOF>", []string{"'}'"}},
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expecting %v errors got %v", len(expected), errs)
+	}
+	for i, e := range expected {
+		actual := errs[i]
+		if actual.Span.Start != (ast.Position{Line: e.line, Column: e.column}) ||
+			actual.Offending != e.offending || !reflect.DeepEqual(actual.Expected, e.expected) {
+			t.Errorf("Error %v should be at %v:%v (%q expecting %v) got %#v", i, e.line, e.column, e.offending, e.expected, actual)
+		}
+	}
+	if tree == nil {
+		t.Fatal("Parse should return the partial tree")
+	}
+	if fmtTree := tree.String(); fmtTree != "{\n\tprintln ok\n\tprintln fine\n\tprintln last\n}" {
+		t.Errorf("Unexpected partial tree %q", fmtTree)
+	}
+}
+
+func TestInvalidEscapeIsOneError(t *testing.T) {
+	tree, err := Parse(`{ echo "bad \q" after }`)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("Expecting a single error got %v", err)
+	}
+	if errs[0].Span.Start != (ast.Position{Line: 1, Column: 8}) || errs[0].Msg != `invalid escape sequence \q` {
+		t.Errorf("Unexpected error %#v", errs[0])
+	}
+	if fmtTree := tree.String(); fmtTree != `{ echo "bad \\q" after }` {
+		t.Errorf("The command should be kept with the string as it is, got %q", fmtTree)
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	for _, c := range []struct {
+		code     string
+		expected *Incomplete
+	}{
+		{"{ func foo [$a] {", &Incomplete{Blocks: 2}},
+		{"{ echo [ 1 2", &Incomplete{Blocks: 1, Lists: 1}},
+		{"{ echo [ 1\n2", &Incomplete{Blocks: 1, Lists: 1}},
+		{"{ echo \"hello\n", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo \"${ echo [ }", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo 'raw", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo #| comment", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo \"a\\", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo \"a\\u12", &Incomplete{Blocks: 1, Text: true}},
+		{"{ echo (len [ 1", &Incomplete{Blocks: 1, Lists: 1, Substitutions: 1}},
+		{"{ echo (= 1 +\n", &Incomplete{Blocks: 1, Substitutions: 1}},
+		{"{ echo %[ a", &Incomplete{Blocks: 1, Lists: 1}},
+		{"{ echo a }", nil},
+		{"{ echo ] {", nil},
+		{"{ echo a }}", nil},
+		{"{ echo ]", nil},
+		{"{ echo [ ] ] [", nil},
+		{"{ echo ) (", nil},
+		{"{ echo \"${ echo [ }\"", nil},
+		{"{ echo \"bad \\q", nil},
+		{"{ echo \"bad \\q\"", nil},
+	} {
+		_, err := ParsePartial(c.code)
+		if c.expected == nil {
+			if _, incomplete := err.(*Incomplete); incomplete {
+				t.Errorf("Code %q should not be considered incomplete", c.code)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(err, c.expected) {
+			t.Errorf("Code %q should be incomplete with %#v got %#v", c.code, c.expected, err)
+		}
+	}
+}
+
+func TestFeeder(t *testing.T) {
+	var f Feeder
+	for _, line := range []string{"{\n", "\tfunc foo [$a] {\n", "\t\tprintln $a\n", "\t}\n"} {
+		if _, err := f.Feed(line); err == nil {
+			t.Fatalf("Feeder should wait for more input after %q", f.Pending())
+		}
+	}
+	tree, err := f.Feed("}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.String() != "{ func foo [ $a ] { println $a } }" {
+		t.Errorf("Unexpected tree %q", tree.String())
+	}
+	if f.Pending() != "" {
+		t.Errorf("Feeder should be reset after a complete script got %q", f.Pending())
+	}
+	if _, err := f.Feed("{ echo ] }"); err == nil {
+		t.Error("Feeder should report invalid input")
+	}
+	if f.Pending() != "" {
+		t.Error("Feeder should discard invalid input")
+	}
+}
+
+func TestParseProgram(t *testing.T) {
+	code := "#!/usr/bin/env gshell\nlet $a 1; println $a\n\nfunc f [] {\n\ttrue\n}\n# the end"
+	tree, err := ParseProgram("test.gsh", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "#!/usr/bin/env gshell\nlet $a 1\nprintln $a\nfunc f [ ] { true }\n# the end\n"
+	if tree.String() != expected {
+		t.Errorf("Program should format to %q got %q", expected, tree.String())
+	}
+	second, err := ParseProgram("test.gsh", tree.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIgnoringSpans(tree, second) {
+		t.Error("Formatted program should produce the same AST")
+	}
+
+	braced, err := Parse("{\n#!/usr/bin/env gshell\nlet $a 1; println $a\n\nfunc f [] {\n\ttrue\n}\n# the end\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalIgnoringSpans(tree.Root(), braced.Root()) {
+		t.Error("Programs should produce the same root as script blocks")
+	}
+
+	if _, err := ParseProgram("test.gsh", "println a\n}\nprintln b"); err == nil {
+		t.Error("Unbalanced braces should not be accepted")
+	}
+}
@@ -0,0 +1,111 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// Incomplete is returned by ParsePartial when the code is valid
+	// so far but ended before every block, list, string, comment
+	// or command substitution was closed
+	Incomplete struct {
+		// Blocks is the number of '{' which were not closed
+		Blocks int
+		// Lists is the number of '[' (or '%[') which were not closed
+		Lists int
+		// Substitutions is the number of '(' which were not closed
+		Substitutions int
+		// Text is true when the code ended inside a string
+		// or block comment
+		Text bool
+	}
+
+	// Feeder accumulates chunks of code, usually the lines typed
+	// in a REPL, until they form a complete script
+	Feeder struct {
+		buf strings.Builder
+	}
+)
+
+func (i *Incomplete) Error() string {
+	return fmt.Sprintf("incomplete input: %v open blocks, %v open lists, %v open substitutions, unterminated text: %v",
+		i.Blocks, i.Lists, i.Substitutions, i.Text)
+}
+
+// ParsePartial works like Parse but returns an *Incomplete error
+// when all the syntax errors are caused by the end of the input,
+// which means more input could still produce a valid script.
+//
+// Code closing more blocks or lists than it opened is never incomplete
+func ParsePartial(code string) (*ast.Ast, error) {
+	tree, err := Parse(code)
+	errs, ok := err.(Errors)
+	if !ok {
+		return tree, err
+	}
+	for _, e := range errs {
+		if !e.atEOF {
+			return tree, err
+		}
+	}
+	inc := openConstructs(code)
+	if inc.Blocks < 0 || inc.Lists < 0 || inc.Substitutions < 0 {
+		return tree, err
+	}
+	return tree, inc
+}
+
+// openConstructs counts the blocks, lists and substitutions
+// that were not closed
+func openConstructs(code string) *Incomplete {
+	inc := &Incomplete{}
+	lex := newLexer(code)
+	for {
+		switch lex.next().kind {
+		case tokEOF:
+			return inc
+		case tokOpenBlock:
+			inc.Blocks++
+		case tokCloseBlock:
+			inc.Blocks--
+		case tokOpenList, tokOpenMap:
+			inc.Lists++
+		case tokCloseList:
+			inc.Lists--
+		case tokOpenParen, tokOpenExpression:
+			inc.Substitutions++
+		case tokCloseParen:
+			inc.Substitutions--
+		case tokUnterminatedString, tokUnterminatedComment:
+			inc.Text = true
+		}
+	}
+}
+
+// Feed appends chunk to the code received so far.
+//
+// If the code is incomplete an *Incomplete error is returned and
+// the code is kept waiting for the next chunk, otherwise the code is
+// parsed, the feeder is reset and the result of Parse is returned
+func (f *Feeder) Feed(chunk string) (*ast.Ast, error) {
+	f.buf.WriteString(chunk)
+	tree, err := ParsePartial(f.buf.String())
+	if _, incomplete := err.(*Incomplete); incomplete {
+		return nil, err
+	}
+	f.Reset()
+	return tree, err
+}
+
+// Pending returns the code waiting for more input
+func (f *Feeder) Pending() string {
+	return f.buf.String()
+}
+
+// Reset discards the code waiting for more input
+func (f *Feeder) Reset() {
+	f.buf.Reset()
+}
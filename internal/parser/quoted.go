@@ -0,0 +1,298 @@
+package parser
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+type (
+	// quotedMatcher finds the end of a double quoted string exactly
+	// like the lexer generated from GShell.g4 would.
+	//
+	// A '$' followed by '{' might start an interpolated block or be
+	// plain text, and a string cut by the end of the input might be an
+	// UNTERMINATED_STRING, so each position can lead to several ends
+	// and the longest one wins. Most strings are read by quickString,
+	// this is only used when the string contains "${" or is not closed
+	quotedMatcher struct {
+		s string
+
+		quotedEnds map[int][]int
+		bracedEnds map[int][]int
+		openQuoted map[int]bool
+		openBraced map[int]bool
+	}
+
+	// offsets is a set of positions waiting to be visited
+	offsets struct {
+		seen    map[int]bool
+		pending []int
+	}
+)
+
+// quickString returns the end of the string which starts at s[start]
+// when it has a single possible end
+func quickString(s string, start int) (int, bool) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i + 1, true
+		case '\\':
+			if i+1 == len(s) {
+				return 0, false
+			}
+			_, size := utf8.DecodeRuneInString(s[i+1:])
+			i += size
+		case '$':
+			if i+1 < len(s) && s[i+1] == '{' {
+				return 0, false
+			}
+		}
+	}
+	return 0, false
+}
+
+func newQuotedMatcher(s string) *quotedMatcher {
+	return &quotedMatcher{
+		s:          s,
+		quotedEnds: make(map[int][]int),
+		bracedEnds: make(map[int][]int),
+		openQuoted: make(map[int]bool),
+		openBraced: make(map[int]bool),
+	}
+}
+
+// token returns the kind and end of the token which starts at
+// s[start], tokError means no token matches the input
+func (m *quotedMatcher) token(start int) (tokenKind, int) {
+	longest := -1
+	for _, end := range m.quoted(start) {
+		if end > longest {
+			longest = end
+		}
+	}
+	switch {
+	case m.open(start):
+		// it wins even over a STRING which ends with the input,
+		// as UNTERMINATED_STRING also matches the EOF
+		return tokUnterminatedString, len(m.s)
+	case longest > 0:
+		return tokString, longest
+	}
+	return tokError, len(m.s)
+}
+
+// invalid explains why no token matched the string at s[start],
+// the invalid escape sequence which prevents it from being closed
+func (m *quotedMatcher) invalid(start int) string {
+	for i := start + 1; i < len(m.s); i++ {
+		if m.s[i] != '\\' {
+			continue
+		}
+		rest := m.s[i+1:]
+		if _, _, err := escape(rest); err != nil && !isEscapePrefix(rest) {
+			return err.Error()
+		}
+		i++
+	}
+	return "token recognition error at: " + quoteToken(m.s[start:])
+}
+
+// quoted returns the ends of: '"' ('\\' . | '$' BRACED | ~["\\])* '"'
+func (m *quotedMatcher) quoted(start int) []int {
+	if ends, ok := m.quotedEnds[start]; ok {
+		return ends
+	}
+	var ends []int
+	todo := newOffsets(start + 1)
+	for p, ok := todo.next(); ok; p, ok = todo.next() {
+		if p >= len(m.s) {
+			continue
+		}
+		switch m.s[p] {
+		case '"':
+			ends = append(ends, p+1)
+		case '\\':
+			if p+1 < len(m.s) {
+				todo.add(p + 1 + m.runeLen(p+1))
+			}
+		case '$':
+			todo.add(p + 1)
+			if p+1 < len(m.s) && m.s[p+1] == '{' {
+				todo.add(m.braced(p + 1)...)
+			}
+		default:
+			todo.add(p + m.runeLen(p))
+		}
+	}
+	m.quotedEnds[start] = ends
+	return ends
+}
+
+// braced returns the ends of: '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* '}'
+func (m *quotedMatcher) braced(start int) []int {
+	if ends, ok := m.bracedEnds[start]; ok {
+		return ends
+	}
+	var ends []int
+	todo := newOffsets(start + 1)
+	for p, ok := todo.next(); ok; p, ok = todo.next() {
+		if p >= len(m.s) {
+			continue
+		}
+		switch m.s[p] {
+		case '}':
+			ends = append(ends, p+1)
+		case '{':
+			todo.add(m.braced(p)...)
+		case '"':
+			todo.add(m.quoted(p)...)
+		case '\'':
+			if end, ok := m.raw(p); ok {
+				todo.add(end)
+			}
+		default:
+			todo.add(p + m.runeLen(p))
+		}
+	}
+	m.bracedEnds[start] = ends
+	return ends
+}
+
+// open reports if the input ends inside the string at s[start]:
+// '"' (VALID_ESCAPE | '$' BRACED | ~["\\])* (OPEN_ESCAPE | '$' OPEN_BRACED)? EOF
+func (m *quotedMatcher) open(start int) bool {
+	if open, ok := m.openQuoted[start]; ok {
+		return open
+	}
+	todo := newOffsets(start + 1)
+	for p, ok := todo.next(); ok; p, ok = todo.next() {
+		if p == len(m.s) {
+			m.openQuoted[start] = true
+			return true
+		}
+		switch m.s[p] {
+		case '"':
+		case '\\':
+			rest := m.s[p+1:]
+			if isEscapePrefix(rest) {
+				m.openQuoted[start] = true
+				return true
+			}
+			if _, size, err := escape(rest); err == nil {
+				todo.add(p + 1 + size)
+			}
+		case '$':
+			todo.add(p + 1)
+			if p+1 < len(m.s) && m.s[p+1] == '{' {
+				if m.openBlock(p + 1) {
+					m.openQuoted[start] = true
+					return true
+				}
+				todo.add(m.braced(p + 1)...)
+			}
+		default:
+			todo.add(p + m.runeLen(p))
+		}
+	}
+	m.openQuoted[start] = false
+	return false
+}
+
+// openBlock reports if the input ends inside the block at s[start]:
+// '{' (STRING | RAW_STRING | BRACED | ~[{}"'])* (OPEN_BRACED | OPEN_STRING | OPEN_RAW_STRING)? EOF
+func (m *quotedMatcher) openBlock(start int) bool {
+	if open, ok := m.openBraced[start]; ok {
+		return open
+	}
+	todo := newOffsets(start + 1)
+	for p, ok := todo.next(); ok; p, ok = todo.next() {
+		if p == len(m.s) {
+			m.openBraced[start] = true
+			return true
+		}
+		switch m.s[p] {
+		case '}':
+		case '{':
+			if m.openBlock(p) {
+				m.openBraced[start] = true
+				return true
+			}
+			todo.add(m.braced(p)...)
+		case '"':
+			if m.open(p) {
+				m.openBraced[start] = true
+				return true
+			}
+			todo.add(m.quoted(p)...)
+		case '\'':
+			end, ok := m.raw(p)
+			if !ok {
+				m.openBraced[start] = true
+				return true
+			}
+			todo.add(end)
+		default:
+			todo.add(p + m.runeLen(p))
+		}
+	}
+	m.openBraced[start] = false
+	return false
+}
+
+// raw returns the end of the raw string at s[start]
+func (m *quotedMatcher) raw(start int) (int, bool) {
+	for i := start + 1; i < len(m.s); i++ {
+		if m.s[i] == '\'' {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+func (m *quotedMatcher) runeLen(p int) int {
+	_, size := utf8.DecodeRuneInString(m.s[p:])
+	return size
+}
+
+func newOffsets(start int) *offsets {
+	return &offsets{
+		seen:    map[int]bool{start: true},
+		pending: []int{start},
+	}
+}
+
+func (o *offsets) add(ps ...int) {
+	for _, p := range ps {
+		if !o.seen[p] {
+			o.seen[p] = true
+			o.pending = append(o.pending, p)
+		}
+	}
+}
+
+func (o *offsets) next() (int, bool) {
+	if len(o.pending) == 0 {
+		return 0, false
+	}
+	p := o.pending[len(o.pending)-1]
+	o.pending = o.pending[:len(o.pending)-1]
+	return p, true
+}
+
+// isEscapePrefix reports if s is the start of an escape
+// sequence (after the backslash) cut by the end of the input
+func isEscapePrefix(s string) bool {
+	if s == "" {
+		return true
+	}
+	if s[0] != 'u' || len(s) >= 5 {
+		return false
+	}
+	for _, r := range s[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package pdata
+
+type (
+	// ArgumentMap is a persistent hash map, Store and Delete return
+	// a new map which shares most of its memory with the original one.
+	//
+	// Like the maps generated by peds, the items are spread among
+	// buckets kept in an ArgumentList, so an update copies a single
+	// bucket and the path to it. Keys must be comparable
+	ArgumentMap struct {
+		buckets *ArgumentList
+		len     int
+	}
+
+	// ArgumentMapItem is a key/value pair of an ArgumentMap
+	ArgumentMapItem struct {
+		Key   Any
+		Value Any
+	}
+
+	argumentMapBucket []ArgumentMapItem
+)
+
+// NewArgumentMap returns a map with the given items, when
+// a key is repeated the last value is kept
+func NewArgumentMap(items ...ArgumentMapItem) *ArgumentMap {
+	buckets := make([]argumentMapBucket, bucketCount(len(items)))
+	size := 0
+	for _, item := range items {
+		i := bucketIndex(item.Key, len(buckets))
+		if j := buckets[i].find(item.Key); j >= 0 {
+			buckets[i][j] = item
+			continue
+		}
+		buckets[i] = append(buckets[i], item)
+		size++
+	}
+	aux := make([]Any, len(buckets))
+	for i, b := range buckets {
+		aux[i] = b
+	}
+	return &ArgumentMap{buckets: NewArgumentList(aux...), len: size}
+}
+
+// Len returns the number of items in the map
+func (m *ArgumentMap) Len() int {
+	return m.len
+}
+
+// Load returns the value stored for key
+func (m *ArgumentMap) Load(key Any) (Any, bool) {
+	if m.len == 0 {
+		return nil, false
+	}
+	b := m.bucket(key)
+	if j := b.find(key); j >= 0 {
+		return b[j].Value, true
+	}
+	return nil, false
+}
+
+// Store returns a new map where key is associated with value
+func (m *ArgumentMap) Store(key, value Any) *ArgumentMap {
+	item := ArgumentMapItem{Key: key, Value: value}
+	if float64(m.len+1) > float64(m.buckets.Len())*upperMapLoadFactor {
+		return NewArgumentMap(append(m.ToNativeSlice(), item)...)
+	}
+	i := bucketIndex(key, m.buckets.Len())
+	b := m.buckets.Get(i).(argumentMapBucket)
+	size := m.len
+	var updated argumentMapBucket
+	if j := b.find(key); j >= 0 {
+		updated = append(argumentMapBucket(nil), b...)
+		updated[j] = item
+	} else {
+		updated = append(append(make(argumentMapBucket, 0, len(b)+1), b...), item)
+		size++
+	}
+	return &ArgumentMap{buckets: m.buckets.Set(i, updated), len: size}
+}
+
+// Delete returns a new map without key
+func (m *ArgumentMap) Delete(key Any) *ArgumentMap {
+	if m.len == 0 {
+		return m
+	}
+	i := bucketIndex(key, m.buckets.Len())
+	b := m.buckets.Get(i).(argumentMapBucket)
+	j := b.find(key)
+	if j < 0 {
+		return m
+	}
+	if float64(m.len-1) < float64(m.buckets.Len())*lowerMapLoadFactor && m.buckets.Len() > 1 {
+		items := m.ToNativeSlice()
+		for k, item := range items {
+			if item.Key == key {
+				items = append(items[:k], items[k+1:]...)
+				break
+			}
+		}
+		return NewArgumentMap(items...)
+	}
+	updated := append(append(make(argumentMapBucket, 0, len(b)-1), b[:j]...), b[j+1:]...)
+	return &ArgumentMap{buckets: m.buckets.Set(i, updated), len: m.len - 1}
+}
+
+// Range calls f for every item in the map, in no particular order,
+// users should return false if they want to stop before
+// processing the whole map
+func (m *ArgumentMap) Range(f func(key, value Any) bool) {
+	stop := false
+	m.buckets.Range(func(a Any) bool {
+		for _, item := range a.(argumentMapBucket) {
+			if !f(item.Key, item.Value) {
+				stop = true
+				break
+			}
+		}
+		return !stop
+	})
+}
+
+// ToNativeSlice returns the items of the map
+func (m *ArgumentMap) ToNativeSlice() []ArgumentMapItem {
+	items := make([]ArgumentMapItem, 0, m.len)
+	m.Range(func(key, value Any) bool {
+		items = append(items, ArgumentMapItem{Key: key, Value: value})
+		return true
+	})
+	return items
+}
+
+func (m *ArgumentMap) bucket(key Any) argumentMapBucket {
+	return m.buckets.Get(bucketIndex(key, m.buckets.Len())).(argumentMapBucket)
+}
+
+func (b argumentMapBucket) find(key Any) int {
+	for i, item := range b {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func bucketCount(items int) int {
+	return int(float64(items)/initialMapLoadFactor) + 1
+}
+
+func bucketIndex(key Any, buckets int) int {
+	return int(interfaceHash(key) % uint32(buckets))
+}
@@ -0,0 +1,90 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// BudgetCounter names one of the limits of a VM
+	BudgetCounter string
+
+	// BudgetError is returned when a script goes over one of the limits
+	// of the VM, errors.Is(err, ErrBudgetExceeded) is true for it
+	BudgetError struct {
+		Counter BudgetCounter
+		// Limit is the value of the counter that tripped,
+		// wall-clock limits are given in nanoseconds
+		Limit int64
+	}
+
+	// budget tracks the resources used by a single run
+	budget struct {
+		maxCommands int64
+		maxListSize int
+		wallClock   time.Duration
+
+		commands int64
+		deadline time.Time
+	}
+)
+
+const (
+	BudgetCommands  = BudgetCounter("commands")
+	BudgetCallDepth = BudgetCounter("call-depth")
+	BudgetListSize  = BudgetCounter("list-size")
+	BudgetWallClock = BudgetCounter("wall-clock")
+)
+
+var (
+	ErrBudgetExceeded = errors.New("budget exceeded")
+)
+
+func (b *BudgetError) Error() string {
+	switch b.Counter {
+	case BudgetCommands:
+		return fmt.Sprintf("%v: more than %v commands executed", ErrBudgetExceeded, b.Limit)
+	case BudgetCallDepth:
+		return fmt.Sprintf("%v: more than %v nested calls", ErrBudgetExceeded, b.Limit)
+	case BudgetListSize:
+		return fmt.Sprintf("%v: list or map with more than %v items", ErrBudgetExceeded, b.Limit)
+	case BudgetWallClock:
+		return fmt.Sprintf("%v: running for more than %v", ErrBudgetExceeded, time.Duration(b.Limit))
+	}
+	return fmt.Sprintf("%v: %v limit of %v", ErrBudgetExceeded, b.Counter, b.Limit)
+}
+
+func (b *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// start resets the counters for a new run
+func (b *budget) start() {
+	b.commands = 0
+	b.deadline = time.Time{}
+	if b.wallClock > 0 {
+		b.deadline = time.Now().Add(b.wallClock)
+	}
+}
+
+// spend counts one more command and checks the
+// limits which depend on how long the script runs
+func (b *budget) spend() error {
+	b.commands++
+	if b.maxCommands > 0 && b.commands > b.maxCommands {
+		return &BudgetError{Counter: BudgetCommands, Limit: b.maxCommands}
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return &BudgetError{Counter: BudgetWallClock, Limit: int64(b.wallClock)}
+	}
+	return nil
+}
+
+// checkList fails if a list or map with size items is over the limit
+func (b *budget) checkList(size int) error {
+	if b.maxListSize > 0 && size > b.maxListSize {
+		return &BudgetError{Counter: BudgetListSize, Limit: int64(b.maxListSize)}
+	}
+	return nil
+}
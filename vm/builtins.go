@@ -1,32 +1,42 @@
 package vm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/andrebq/gshell/ast"
 	"github.com/andrebq/gshell/ast/match"
 )
 
 func GShellLetVariable(c *CallStack) {
-	if len(c.RawArgs) != 2 {
-		c.FailWith = errors.New("Invalid use of 'let', should be: 'let <$variableName> <value>'")
+	if len(c.RawArgs) != 2 || checkPattern(c.RawArgs[0]) != nil {
+		c.FailWith = errors.New("Invalid use of 'let', should be: 'let <$variableName> <value>' or 'let [<$variableName>... [<$rest>...]] <list>'")
 		return
 	}
-	varname := c.RawArgs[0].(ast.Var).Name()
-	parent := c.Context.parent
-	if !parent.CanBind(varname) {
-		c.FailWith = fmt.Errorf("Variable %v is already defined in the context", varname.Text())
+	val, err := c.VM.Eval(c.Context, c.RawArgs[1])
+	if err != nil {
+		c.FailWith = err
 		return
 	}
-	val, err := c.VM.Eval(c.Context, c.RawArgs[1])
+	bindings, err := destructure(c.RawArgs[0], val)
 	if err != nil {
 		c.FailWith = err
 		return
 	}
-	parent.Let(varname, val)
+	parent := c.Context.parent
+	for _, b := range bindings {
+		if !parent.CanBind(b.name) {
+			c.FailWith = fmt.Errorf("Variable %v is already defined in the context", b.name.Text())
+			return
+		}
+	}
+	for _, b := range bindings {
+		parent.Let(b.name, b.value)
+	}
 	c.ReturnValue = val
 }
 
@@ -38,14 +48,7 @@ func GShellPrintln(c *CallStack) {
 			c.FailWith = err
 			return
 		}
-		switch v := v.(type) {
-		case ast.Symbol:
-			parts[i] = v.Text()
-		case ast.Number:
-			parts[i] = strconv.FormatFloat(v.Float64(), 'f', -1, 64)
-		default:
-			parts[i] = fmt.Sprintf("%v", v)
-		}
+		parts[i] = render(v)
 	}
 	c.VM.enqueueValue(ast.ScopedSymbol(localSym, stdoutSym), c.Context, strings.Join(parts, " ")+"\n")
 	c.ReturnValue = trueSym
@@ -63,17 +66,20 @@ func GShellGuard(c *CallStack) {
 	condEval, err := c.VM.evalScript(condCtx, cond)
 	if err != nil {
 		c.FailWith = err
+		return
 	}
 	var allowed bool
 	if err = c.VM.CastTo(condCtx, condEval, &allowed); err != nil {
 		c.FailWith = err
+		return
 	}
 	if !allowed {
 		c.ReturnValue = false
 		return
 	}
 
-	bodyEval, err := c.VM.evalScript(c.Context, body)
+	// the body is in tail position when guard is
+	bodyEval, err := c.VM.evalBlock(c.Context, body, c.tail)
 	if err != nil {
 		c.FailWith = err
 		return
@@ -132,6 +138,7 @@ func GShellSwitch(c *CallStack) {
 			}
 			if err := c.VM.CastTo(ctx, val, &boolVal); err != nil {
 				c.FailWith = err
+				return
 			}
 		} else {
 			boolVal = true
@@ -139,20 +146,24 @@ func GShellSwitch(c *CallStack) {
 
 		if boolVal {
 			ctx = NewContext(c.Context)
-			c.ReturnValue, c.FailWith = c.VM.Eval(ctx, sc.body)
+			c.ReturnValue, c.FailWith = c.VM.evalBlock(ctx, sc.body, c.tail)
 			return
 		}
 	}
 }
 
 func GShellLoop(c *CallStack) {
-	loopUsage := fmt.Sprintf("loop <variable-name> from <start-point> to <end-point> <{body}>")
+	loopUsage := fmt.Sprintf("loop <variable-name> from <start-point> to <end-point> <{body}> or loop <variable-name> --from=<start-point> --to=<end-point> <{body}>")
 	var varName ast.Symbol
 	var fromArg, toArg ast.Argument
 	var body *ast.Script
 	guard := match.Guard(match.AnySymbol(&varName), match.Symbol(fromSym), match.Head(&fromArg),
 		match.Symbol(toSym), match.Head(&toArg), match.Script(&body))
-	if !match.Apply(&c.RawArgs, guard) {
+	flags := c.RawFlags
+	withFlags := match.Guard(match.AnySymbol(&varName), match.Script(&body))
+	flagGuard := match.Flags(match.Flag(fromSym, &fromArg), match.Flag(toSym, &toArg), match.NoFlags())
+	if !match.Apply(&c.RawArgs, guard) &&
+		!(match.ApplyFlags(&flags, flagGuard) && match.Apply(&c.RawArgs, withFlags)) {
 		c.FailWith = errors.New(loopUsage)
 		return
 	}
@@ -169,6 +180,12 @@ func GShellLoop(c *CallStack) {
 
 	lst := ast.NilList()
 	for (rev && fromIdx >= toIdx) || (!rev && fromIdx <= toIdx) {
+		if c.FailWith = c.Context.goCtx.Err(); c.FailWith != nil {
+			return
+		}
+		if c.FailWith = c.VM.budget.spend(); c.FailWith != nil {
+			return
+		}
 		ctx := NewContext(c.Context)
 		ctx.Set(varName, ast.NewNumber(fromIdx))
 		val, err := c.VM.Eval(ctx, body)
@@ -176,7 +193,19 @@ func GShellLoop(c *CallStack) {
 			c.FailWith = err
 			return
 		}
-		lst = lst.Append(val.(ast.Argument))
+		// iterations which do not produce a value
+		// (ie.: a switch without a match) are skipped
+		if val != nil {
+			item, err := encodeValue(val)
+			if err != nil {
+				c.FailWith = err
+				return
+			}
+			lst = lst.Append(item)
+			if c.FailWith = c.VM.budget.checkList(lst.Len()); c.FailWith != nil {
+				return
+			}
+		}
 
 		if rev {
 			fromIdx--
@@ -188,30 +217,31 @@ func GShellLoop(c *CallStack) {
 }
 
 func GShellFunc(c *CallStack) {
-	// for now, let's just now allow functions to define functions
-	// this is cutting on the capability of creating closures
-	// which is kind of important on a purely immutable language...
-	//
-	// but bash doesn't have it and it kinda works ok there :-)
-	// so no fancy schmancy high-order functions for you!
-	if c.Context.isFunction {
-		c.FailWith = errors.New("Functions cannot define other functions... sorry :(")
-		return
-	}
-	funcUsage := fmt.Sprintf("func <function-name> [<variable list>]  <{function body}>")
+	funcUsage := fmt.Sprintf("func <function-name> [<variable or list pattern>... [%%[<flag> <default value>...]]]  <{function body}>")
 	var funcName ast.Symbol
-	var argList *ast.List
-	var body *ast.Script
-	guard := match.Guard(match.AnySymbol(&funcName),
-		match.ListOf(&argList, func(a ast.Argument) bool {
-			_, isVar := a.(ast.Var)
-			return isVar
-		}),
-		match.Script(&body))
-	if !match.Apply(&c.RawArgs, guard) {
+	if !match.Apply(&c.RawArgs, match.AnySymbol(&funcName)) {
 		c.FailWith = errors.New(funcUsage)
 		return
 	}
+	args, flags, body, ok := funcDefinition(c, funcUsage)
+	if !ok {
+		return
+	}
+
+	if c.Context.IsFunction() {
+		// functions defined by other functions are bound in the
+		// scope of the enclosing call, the new function captures
+		// that scope so it can call itself
+		scope := c.Context
+		if !scope.CanBind(funcName) {
+			c.FailWith = fmt.Errorf("Function %v is already defined in the context", funcName)
+			return
+		}
+		fn := c.VM.newFunction(c.Context, c.VM.currentModule, funcName, args, flags, body)
+		scope.Let(funcName, fn)
+		c.ReturnValue = fn
+		return
+	}
 
 	cm := c.VM.currentModule
 	module := c.VM.modules[c.VM.currentModule]
@@ -220,17 +250,180 @@ func GShellFunc(c *CallStack) {
 		return
 	}
 
-	if _, defined := module.definitions.Get(funcName); defined {
+	if !module.definitions.CanBind(funcName) {
 		c.FailWith = fmt.Errorf("Module %v already contains a function called %v.", cm, funcName)
 		return
 	}
 
-	fn := c.VM.newFunction(c.Context, cm, funcName, argList, body)
+	fn := c.VM.newFunction(c.Context, cm, funcName, args, flags, body)
 	module.definitions.Set(funcName, fn)
 	c.ReturnValue = fn
 	return
 }
 
+// GShellFn creates an anonymous function which captures
+// the context where it was defined
+func GShellFn(c *CallStack) {
+	args, flags, body, ok := funcDefinition(c, "fn [<variable or list pattern>... [%[<flag> <default value>...]]]  <{function body}>")
+	if !ok {
+		return
+	}
+	c.ReturnValue = c.VM.newFunction(c.Context, c.VM.currentModule, fnSym, args, flags, body)
+}
+
+// GShellTimeout fails if its body does not finish within the given
+// duration, which is a number of seconds or a text with a Go duration
+// (ie.: timeout "500ms" { ... })
+func GShellTimeout(c *CallStack) {
+	var limit ast.Argument
+	var body *ast.Script
+	if !match.Apply(&c.RawArgs, match.Guard(match.Head(&limit), match.Script(&body))) || len(c.RawArgs) != 0 {
+		c.FailWith = errors.New("timeout <duration> <{body}>")
+		return
+	}
+	value, err := c.VM.Eval(c.Context, limit)
+	if err != nil {
+		c.FailWith = err
+		return
+	}
+	var d time.Duration
+	switch value := value.(type) {
+	case float64:
+		d = time.Duration(value * float64(time.Second))
+	default:
+		if d, err = time.ParseDuration(render(value)); err != nil {
+			c.FailWith = fmt.Errorf("Invalid duration %v, use a number of seconds or a text like \"500ms\"", render(value))
+			return
+		}
+	}
+
+	goCtx, cancel := context.WithTimeout(c.Context.goCtx, d)
+	defer cancel()
+	ctx := NewContext(c.Context)
+	ctx.goCtx = goCtx
+	c.ReturnValue, c.FailWith = c.VM.evalScript(ctx, body)
+	// only report the deadline set by this block, the parent
+	// context might have been cancelled for another reason
+	if c.FailWith != nil && goCtx.Err() != nil && c.Context.goCtx.Err() == nil {
+		c.ReturnValue = nil
+		c.FailWith = fmt.Errorf("Block did not finish within %v: %w", d, context.DeadlineExceeded)
+	}
+}
+
+// GShellReturn stops the function being called,
+// its argument (if any) is the value returned by it
+func GShellReturn(c *CallStack) {
+	if len(c.RawArgs) > 1 {
+		c.FailWith = errors.New("return [<value>]")
+		return
+	}
+	var value Value
+	if len(c.RawArgs) == 1 {
+		var err error
+		if value, err = c.VM.Eval(c.Context, c.RawArgs[0]); err != nil {
+			c.FailWith = err
+			return
+		}
+	}
+	c.FailWith = &returnSignal{value: value}
+}
+
+// GShellCall calls the value of its first argument
+// with the remaining arguments and flags
+func GShellCall(c *CallStack) {
+	if len(c.RawArgs) == 0 {
+		c.FailWith = errors.New("call <callable value> [<arguments>...]")
+		return
+	}
+	value, err := c.VM.Eval(c.Context, c.RawArgs[0])
+	if err != nil {
+		c.FailWith = err
+		return
+	}
+	call := &CallStack{
+		VM:       c.VM,
+		RawArgs:  c.RawArgs[1:],
+		RawFlags: c.RawFlags,
+		Context:  c.Context,
+	}
+	c.VM.callValue(call, value)
+	c.ReturnValue, c.FailWith = call.ReturnValue, call.FailWith
+}
+
+// funcDefinition matches the parameters and the body used by func
+// and fn, c fails with usage if they are not valid
+func funcDefinition(c *CallStack, usage string) ([]ast.Argument, *ast.Map, *ast.Script, bool) {
+	var argList *ast.List
+	var body *ast.Script
+	guard := match.Guard(
+		match.ListOf(&argList, func(a ast.Argument) bool {
+			switch a.(type) {
+			case ast.Var, *ast.List, *ast.Map:
+				return true
+			}
+			return false
+		}),
+		match.Script(&body))
+	if !match.Apply(&c.RawArgs, guard) || len(c.RawArgs) != 0 {
+		c.FailWith = errors.New(usage)
+		return nil, nil, nil, false
+	}
+	args, flags, err := funcParams(argList)
+	if err != nil {
+		c.FailWith = err
+		return nil, nil, nil, false
+	}
+	flags, err = c.VM.evalMap(c.Context, flags)
+	if err != nil {
+		c.FailWith = err
+		return nil, nil, nil, false
+	}
+	return args, flags, body, true
+}
+
+// funcParams splits the parameters of a function into its arguments
+// and the flags it accepts, flags are declared by a map at the end
+// of the list: [$a [$b $c] %[ verbose false ]]
+func funcParams(params *ast.List) ([]ast.Argument, *ast.Map, error) {
+	var args []ast.Argument
+	flags := ast.NilMap()
+	var err error
+	last := params.ForEach(func(a ast.Argument) bool {
+		switch a := a.(type) {
+		case *ast.Map:
+			flags = a
+			a.ForEach(func(k, _ ast.Argument) bool {
+				if _, isSym := k.(ast.Symbol); !isSym {
+					err = fmt.Errorf("Flag names must be symbols got %v", k)
+				}
+				return err == nil
+			})
+			return false
+		default:
+			err = checkPattern(a)
+			args = append(args, a)
+		}
+		return err == nil
+	})
+	if err == nil && last != params.Len() {
+		err = errors.New("Flags must be the last item in the list of parameters")
+	}
+	return args, flags, err
+}
+
+// render converts a value to the text used by println
+// and string interpolation
+func render(v Value) string {
+	switch v := v.(type) {
+	case ast.Symbol:
+		return v.Text()
+	case ast.Number:
+		return strconv.FormatFloat(v.Float64(), 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
 func MakeIdentityProcess(val ast.Argument) ProcessFunc {
 	return func(c *CallStack) {
 		c.ReturnValue = val
@@ -0,0 +1,114 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// Capability groups builtins which give scripts access to
+	// the same kind of resource, so they can be denied together
+	Capability string
+
+	// PermissionError is returned when a script calls a builtin
+	// which is not allowed by the VM, errors.Is(err, ErrPermissionDenied)
+	// is true for it.
+	//
+	// Capability is empty when the builtin was left out by WithBuiltins
+	PermissionError struct {
+		Command    ast.Symbol
+		Capability Capability
+	}
+
+	builtin struct {
+		name       ast.Symbol
+		capability Capability
+		process    Process
+		// flags is true for builtins which check their own flags,
+		// the others fail when called with any
+		flags bool
+	}
+)
+
+const (
+	// CapCore covers variables, control flow, functions,
+	// modules and error handling
+	CapCore = Capability("core")
+	// CapOutput covers writing to stdout and stderr
+	CapOutput = Capability("output")
+	// CapFilesystem covers builtins reading or writing files (ie.: import)
+	CapFilesystem = Capability("filesystem")
+	// CapProcess covers builtins starting other processes
+	CapProcess = Capability("process")
+	// CapNetwork covers builtins using the network
+	CapNetwork = Capability("network")
+)
+
+var (
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// defaultBuiltins returns the builtins registered by NewVM
+func defaultBuiltins() []builtin {
+	return []builtin{
+		{printlnSym, CapOutput, ProcessFunc(GShellPrintln), false},
+		{letSym, CapCore, ProcessFunc(GShellLetVariable), false},
+		{switchSym, CapCore, ProcessFunc(GShellSwitch), false},
+		{trueSym, CapCore, MakeIdentityProcess(trueSym), false},
+		{falseSym, CapCore, MakeIdentityProcess(falseSym), false},
+		{guardSym, CapCore, ProcessFunc(GShellGuard), false},
+		{loop, CapCore, ProcessFunc(GShellLoop), true},
+		{funcSym, CapCore, ProcessFunc(GShellFunc), false},
+		{fnSym, CapCore, ProcessFunc(GShellFn), false},
+		{callSym, CapCore, ProcessFunc(GShellCall), true},
+		{returnSym, CapCore, ProcessFunc(GShellReturn), false},
+		{moduleSym, CapCore, ProcessFunc(GShellModule), false},
+		{exportSym, CapCore, ProcessFunc(GShellExport), false},
+		{importSym, CapFilesystem, ProcessFunc(GShellImport), false},
+		{trySym, CapCore, ProcessFunc(GShellTry), false},
+		{failSym, CapCore, ProcessFunc(GShellFail), true},
+		{timeoutSym, CapCore, ProcessFunc(GShellTimeout), false},
+	}
+}
+
+func (p *PermissionError) Error() string {
+	if p.Capability == "" {
+		return fmt.Sprintf("%v: command %v is not in the allowed builtin list", ErrPermissionDenied, p.Command)
+	}
+	return fmt.Sprintf("%v: command %v requires the %v capability", ErrPermissionDenied, p.Command, p.Capability)
+}
+
+func (p *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// registerBuiltins adds the builtins allowed by the options
+// given to NewVM, the others are kept in v.denied so calling
+// them fails with a PermissionError
+func (v *VM) registerBuiltins(builtins []builtin) {
+	known := make(map[ast.Symbol]struct{}, len(builtins))
+	for _, b := range builtins {
+		known[b.name] = struct{}{}
+		if v.deniedCaps[b.capability] {
+			v.denied[b.name] = b.capability
+			continue
+		}
+		if _, allowed := v.allowed[b.name]; v.allowed != nil && !allowed {
+			v.denied[b.name] = ""
+			continue
+		}
+		if !b.flags {
+			v.builtins[b.name] = rejectFlags(b.name, b.process)
+			continue
+		}
+		v.builtins[b.name] = b.process
+	}
+	for name := range v.allowed {
+		if _, found := known[name]; !found {
+			v.configErr = fmt.Errorf("Builtin %v given to WithBuiltins does not exist", name)
+			return
+		}
+	}
+}
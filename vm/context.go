@@ -22,8 +22,13 @@ func NewContext(parent *Context) *Context {
 }
 
 func NewFunctionContext(parent *Context) *Context {
+	goCtx := context.Background()
+	if parent != nil {
+		goCtx = parent.goCtx
+	}
 	return &Context{
 		parent:     parent,
+		goCtx:      goCtx,
 		refs:       make(map[ast.Symbol]Value),
 		isFunction: true,
 	}
@@ -0,0 +1,163 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// Error is returned by the VM when a command fails,
+	// it points to the region of the code which caused the failure
+	//
+	// Use %+v to print the error followed by its trace
+	Error struct {
+		Span ast.Span
+		// Command is the name of the command which failed
+		Command ast.Symbol
+		Err     error
+		// Trace holds the frames active when the command failed
+		Trace Trace
+	}
+
+	undefinedVariableError struct {
+		v ast.Var
+	}
+
+	// returnSignal is used by return to unwind the blocks
+	// being evaluated up to the function being called
+	returnSignal struct {
+		value Value
+	}
+
+	// tailCall is used by a command in tail position to hand
+	// the function it calls back to callFunction
+	tailCall struct {
+		fn   *function
+		call *CallStack
+		// module is where the arguments of call are evaluated
+		module ast.Symbol
+	}
+
+	// failure is raised by the fail builtin
+	failure struct {
+		message string
+		kind    string
+	}
+
+	// stackOverflowError is returned when a script nests more
+	// calls than the maximum call depth of the VM
+	stackOverflowError struct {
+		depth  int
+		frames Trace
+	}
+)
+
+// asError returns err as an *Error, keeping the position and the
+// trace of the innermost *Error wrapped by it
+func asError(err error) error {
+	var vmErr *Error
+	if err == nil || !errors.As(err, &vmErr) || err == error(vmErr) {
+		return err
+	}
+	return &Error{Span: vmErr.Span, Command: vmErr.Command, Err: err, Trace: vmErr.Trace}
+}
+
+func (e *Error) Error() string {
+	var inner *Error
+	if errors.As(e.Err, &inner) {
+		// the position is already part of the message
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v: %v", e.Span, e.Err)
+}
+
+// Format prints the trace after the error when
+// the %+v verb is used
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	case verb == 'v' && s.Flag('+') && len(e.Trace) > 0:
+		fmt.Fprintf(s, "%v\n\ntrace:\n%v", e.message(), e.Trace)
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// message returns the error without the frames listed by a
+// stack overflow, since they are the same ones from the trace
+func (e *Error) message() string {
+	msg := e.Error()
+	var overflow *stackOverflowError
+	if errors.As(e.Err, &overflow) {
+		msg = strings.Replace(msg, overflow.Error(), overflow.summary(), 1)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func (e *undefinedVariableError) Error() string {
+	return fmt.Sprintf("Variable %v is not defined", e.v)
+}
+
+func (r *returnSignal) Error() string {
+	return "return used outside of a function"
+}
+
+func (t *tailCall) Error() string {
+	return fmt.Sprintf("tail call to %v used outside of a function", t.fn.name)
+}
+
+func (f *failure) Error() string {
+	return f.message
+}
+
+// Unwrap makes the overflow match ErrBudgetExceeded
+func (s *stackOverflowError) Unwrap() error {
+	return &BudgetError{Counter: BudgetCallDepth, Limit: int64(s.depth)}
+}
+
+func newStackOverflowError(depth int, frames Trace) *stackOverflowError {
+	return &stackOverflowError{depth: depth, frames: frames}
+}
+
+func (s *stackOverflowError) summary() string {
+	return fmt.Sprintf("stack overflow: more than %v nested calls", s.depth)
+}
+
+// Error lists the most recent frames, innermost first
+func (s *stackOverflowError) Error() string {
+	var buf strings.Builder
+	buf.WriteString(s.summary())
+	for i := 0; i < len(s.frames) && i < maxTraceFrames; i++ {
+		fmt.Fprintf(&buf, "\n\t%v", s.frames[i])
+	}
+	if hidden := len(s.frames) - maxTraceFrames; hidden > 0 {
+		fmt.Fprintf(&buf, "\n\t... %v more", hidden)
+	}
+	return buf.String()
+}
+
+// withSpan wraps err with the span of the command that caused it and
+// the current trace, errors which already carry a span are kept as
+// they are since they point to a more specific location
+func (v *VM) withSpan(cmd *ast.Cmd, err error) error {
+	var vmErr *Error
+	if errors.As(err, &vmErr) {
+		return err
+	}
+	span := cmd.Span()
+	var undefined *undefinedVariableError
+	if errors.As(err, &undefined) && undefined.v.Span().IsValid() {
+		span = undefined.v.Span()
+	}
+	if !span.IsValid() {
+		return err
+	}
+	return &Error{Span: span, Command: cmd.Command(), Err: err, Trace: v.trace()}
+}
@@ -0,0 +1,155 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+var (
+	errDivisionByZero = errors.New("division by zero")
+)
+
+// evalBinary applies the operator to both operands, arithmetic
+// produces ast.Number values while comparisons and boolean
+// operators produce the true/false symbols
+func (v *VM) evalBinary(ctx *Context, b *ast.Binary) (Value, error) {
+	switch b.Operator() {
+	case ast.OpAnd, ast.OpOr:
+		var left bool
+		if err := v.EvalAndCast(ctx, b.Left(), &left); err != nil {
+			return nil, err
+		}
+		// the right side is only evaluated when needed
+		if left == (b.Operator() == ast.OpOr) {
+			return boolSymbol(left), nil
+		}
+		var right bool
+		if err := v.EvalAndCast(ctx, b.Right(), &right); err != nil {
+			return nil, err
+		}
+		return boolSymbol(right), nil
+	}
+
+	left, err := v.Eval(ctx, b.Left())
+	if err != nil {
+		return nil, err
+	}
+	right, err := v.Eval(ctx, b.Right())
+	if err != nil {
+		return nil, err
+	}
+	switch b.Operator() {
+	case ast.OpEq:
+		return boolSymbol(v.equals(ctx, left, right)), nil
+	case ast.OpNe:
+		return boolSymbol(!v.equals(ctx, left, right)), nil
+	case ast.OpLt, ast.OpLe, ast.OpGt, ast.OpGe:
+		cmp, err := v.compare(ctx, left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch b.Operator() {
+		case ast.OpLt:
+			return boolSymbol(cmp < 0), nil
+		case ast.OpLe:
+			return boolSymbol(cmp <= 0), nil
+		case ast.OpGt:
+			return boolSymbol(cmp > 0), nil
+		default:
+			return boolSymbol(cmp >= 0), nil
+		}
+	}
+
+	var x, y float64
+	if err := v.CastTo(ctx, left, &x); err != nil {
+		return nil, err
+	}
+	if err := v.CastTo(ctx, right, &y); err != nil {
+		return nil, err
+	}
+	switch b.Operator() {
+	case ast.OpAdd:
+		return ast.NewNumber(x + y), nil
+	case ast.OpSub:
+		return ast.NewNumber(x - y), nil
+	case ast.OpMul:
+		return ast.NewNumber(x * y), nil
+	case ast.OpDiv:
+		if y == 0 {
+			return nil, errDivisionByZero
+		}
+		return ast.NewNumber(x / y), nil
+	case ast.OpMod:
+		if y == 0 {
+			return nil, errDivisionByZero
+		}
+		return ast.NewNumber(math.Mod(x, y)), nil
+	}
+	return nil, fmt.Errorf("Operator %v is not supported", b.Operator())
+}
+
+func (v *VM) evalUnary(ctx *Context, u *ast.Unary) (Value, error) {
+	switch u.Operator() {
+	case ast.OpNot:
+		var b bool
+		if err := v.EvalAndCast(ctx, u.Operand(), &b); err != nil {
+			return nil, err
+		}
+		return boolSymbol(!b), nil
+	case ast.OpNeg:
+		var n float64
+		if err := v.EvalAndCast(ctx, u.Operand(), &n); err != nil {
+			return nil, err
+		}
+		return ast.NewNumber(-n), nil
+	}
+	return nil, fmt.Errorf("Operator %v is not supported", u.Operator())
+}
+
+// equals compares numbers by their value regardless of their type,
+// other values must be equal
+func (v *VM) equals(ctx *Context, left, right Value) bool {
+	var x, y float64
+	if v.CastTo(ctx, left, &x) == nil && v.CastTo(ctx, right, &y) == nil {
+		return x == y
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// compare returns -1, 0 or 1 if left is less than, equal or
+// greater than right, only numbers and strings can be compared
+func (v *VM) compare(ctx *Context, left, right Value) (int, error) {
+	var x, y float64
+	if v.CastTo(ctx, left, &x) == nil && v.CastTo(ctx, right, &y) == nil {
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	a, aok := left.(string)
+	b, bok := right.(string)
+	if !aok || !bok {
+		return 0, fmt.Errorf("Cannot compare %T with %T", left, right)
+	}
+	switch {
+	case a < b:
+		return -1, nil
+	case a > b:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func boolSymbol(b bool) ast.Symbol {
+	if b {
+		return trueSym
+	}
+	return falseSym
+}
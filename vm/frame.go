@@ -0,0 +1,118 @@
+package vm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// Frame is a function, or the top-level of a module, being
+	// evaluated by the VM and the command it is executing
+	Frame struct {
+		// Function is empty for the top-level of a module
+		Function ast.Symbol
+		Module   ast.Symbol
+		// Command is the name of the command as written in the
+		// script, commands called through a variable keep the $
+		Command string
+		// Span of the command, it might not be valid
+		Span ast.Span
+		// TailCalls counts the frames replaced by this one
+		TailCalls int
+	}
+
+	// Trace lists the frames active when an error happened,
+	// the innermost frame comes first
+	Trace []Frame
+)
+
+// maxTraceFrames is the number of frames printed by
+// traces and stack overflow errors
+const maxTraceFrames = 10
+
+func newFrame(fn *function) Frame {
+	return Frame{Function: fn.name, Module: fn.module}
+}
+
+// tailCall returns the frame which replaces f when it calls fn
+// from a tail position
+func (f Frame) tailCall(fn *function) Frame {
+	next := newFrame(fn)
+	next.TailCalls = f.TailCalls + 1
+	return next
+}
+
+// String returns the qualified name of the function (ie.: strings/upper)
+// or the name of the module for top-level frames, followed by the number
+// of frames it replaced with tail calls
+func (f Frame) String() string {
+	if f.Function.Text() == "" {
+		return fmt.Sprintf("module %v", f.Module)
+	}
+	switch f.TailCalls {
+	case 0:
+		return fmt.Sprintf("%v/%v", f.Module, f.Function)
+	case 1:
+		return fmt.Sprintf("%v/%v (tail call)", f.Module, f.Function)
+	default:
+		return fmt.Sprintf("%v/%v (%v tail calls)", f.Module, f.Function, f.TailCalls)
+	}
+}
+
+// String prints one frame after the other with the command
+// each one was executing, similar to the traces of Go panics,
+// only the innermost frames are printed
+//
+//	main/greet
+//		line 2:3 println
+//	module main
+//		line 5:1 greet
+func (t Trace) String() string {
+	var buf strings.Builder
+	for i, f := range t {
+		if i == maxTraceFrames {
+			fmt.Fprintf(&buf, "\n... %v more", len(t)-maxTraceFrames)
+			break
+		}
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(f.String())
+		if f.Command == "" {
+			continue
+		}
+		buf.WriteString("\n\t")
+		if f.Span.IsValid() {
+			buf.WriteString(f.Span.String() + " ")
+		}
+		buf.WriteString(f.Command)
+	}
+	return buf.String()
+}
+
+// commandName returns the name of cmd as written in the script
+func commandName(cmd *ast.Cmd) string {
+	if v, ok := cmd.CommandVar(); ok {
+		return v.String()
+	}
+	return cmd.Command().Text()
+}
+
+func (v *VM) pushFrame(f Frame) {
+	v.frames = append(v.frames, f)
+}
+
+func (v *VM) popFrame() {
+	v.frames = v.frames[:len(v.frames)-1]
+}
+
+// trace returns a copy of the frames, innermost first
+func (v *VM) trace() Trace {
+	t := make(Trace, len(v.frames))
+	for i, f := range v.frames {
+		t[len(t)-1-i] = f
+	}
+	return t
+}
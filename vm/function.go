@@ -1,13 +1,32 @@
 package vm
 
-import "github.com/andrebq/gshell/ast"
+import (
+	"fmt"
+
+	"github.com/andrebq/gshell/ast"
+)
 
 type (
 	function struct {
 		module   ast.Symbol
 		upvalues *Context
 		name     ast.Symbol
-		args     []ast.Var
-		body     *ast.Script
+		// args are the patterns which bind
+		// the arguments of the function
+		args []ast.Argument
+		// flags maps the name of the flags accepted
+		// by the function to their default values
+		flags *ast.Map
+		body  *ast.Script
 	}
 )
+
+// Run calls the function, so functions can be
+// stored in variables and called like any other Process
+func (f *function) Run(c *CallStack) {
+	c.VM.callFunction(c, f)
+}
+
+func (f *function) String() string {
+	return fmt.Sprintf("<function %v>", f.name)
+}
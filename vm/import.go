@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+	"github.com/andrebq/gshell/ast/match"
+	"github.com/andrebq/gshell/internal/parser"
+)
+
+// GShellImport loads the module defined by <module-name>.gsh from the
+// search path, the module is called after the last part of its name
+// unless an alias is given: import lib/strings as str
+func GShellImport(c *CallStack) {
+	importUsage := "import <module-name> [as <alias>]"
+	var name, alias ast.Symbol
+	if !match.Apply(&c.RawArgs, match.AnySymbol(&name)) {
+		c.FailWith = errors.New(importUsage)
+		return
+	}
+	if len(c.RawArgs) > 0 && !match.Apply(&c.RawArgs, match.Guard(match.Symbol(asSym), match.AnySymbol(&alias))) ||
+		len(c.RawArgs) != 0 {
+		c.FailWith = errors.New(importUsage)
+		return
+	}
+	if alias == (ast.Symbol{}) {
+		var err error
+		if alias, err = ast.NewSymbol(path.Base(name.Text())); err != nil {
+			c.FailWith = fmt.Errorf("Invalid module name %v", name)
+			return
+		}
+	}
+	if strings.Contains(alias.Text(), "/") {
+		c.FailWith = fmt.Errorf("Invalid module name %v, it cannot contain '/'", alias)
+		return
+	}
+	file, err := c.VM.findModule(name)
+	if err != nil {
+		c.FailWith = err
+		return
+	}
+	if c.FailWith = c.VM.importModule(c.Context.goCtx, alias, file); c.FailWith != nil {
+		return
+	}
+	c.ReturnValue = alias
+}
+
+// findModule returns the absolute path of the first file
+// in the search path which defines the module
+func (v *VM) findModule(name ast.Symbol) (string, error) {
+	dirs := v.searchPath
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	file := filepath.FromSlash(name.Text()) + ".gsh"
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, file)
+		if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+			continue
+		}
+		return filepath.Abs(candidate)
+	}
+	return "", fmt.Errorf("Module %v not found in the search path %v", name, strings.Join(dirs, string(filepath.ListSeparator)))
+}
+
+// importModule registers the module defined by file as alias, each file
+// is only evaluated once, later imports reuse the same module
+func (v *VM) importModule(goCtx context.Context, alias ast.Symbol, file string) error {
+	for i, f := range v.importing {
+		if f == file {
+			cycle := append(append([]string(nil), v.importing[i:]...), file)
+			return fmt.Errorf("import cycle: %v", strings.Join(cycle, " -> "))
+		}
+	}
+	cached, found := v.imports[file]
+	if existing, defined := v.modules[alias]; defined {
+		if found && existing == cached {
+			return nil
+		}
+		return fmt.Errorf("Module %v is already defined", alias)
+	}
+	if found {
+		v.modules[alias] = cached
+		return nil
+	}
+
+	code, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	tree, err := parser.ParseProgram(file, string(code))
+	if err != nil {
+		return err
+	}
+	v.importing = append(v.importing, file)
+	module, err := v.defineModule(goCtx, alias, tree.Root())
+	v.importing = v.importing[:len(v.importing)-1]
+	if err != nil {
+		return err
+	}
+	v.imports[file] = module
+	return nil
+}
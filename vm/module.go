@@ -0,0 +1,117 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrebq/gshell/ast"
+	"github.com/andrebq/gshell/ast/match"
+)
+
+// GShellModule declares a new module, functions defined by
+// the body belong to the module and variables defined by it
+// are only visible to those functions
+func GShellModule(c *CallStack) {
+	var name ast.Symbol
+	var body *ast.Script
+	if !match.Apply(&c.RawArgs, match.Guard(match.AnySymbol(&name), match.Script(&body))) || len(c.RawArgs) != 0 {
+		c.FailWith = errors.New("module <module-name> <{module body}>")
+		return
+	}
+	if strings.Contains(name.Text(), "/") {
+		c.FailWith = fmt.Errorf("Invalid module name %v, it cannot contain '/'", name)
+		return
+	}
+	if _, exists := c.VM.modules[name]; exists {
+		c.FailWith = fmt.Errorf("Module %v is already defined", name)
+		return
+	}
+	if _, c.FailWith = c.VM.defineModule(c.Context.goCtx, name, body); c.FailWith != nil {
+		return
+	}
+	c.ReturnValue = name
+}
+
+// defineModule registers a new module called name and
+// evaluates its body, the module is discarded if the body fails
+func (v *VM) defineModule(goCtx context.Context, name ast.Symbol, body *ast.Script) (*Module, error) {
+	module := EmptyModule(v.rootCtx)
+	v.modules[name] = module
+
+	state := NewContext(v.rootCtx)
+	state.goCtx = goCtx
+	parent := v.currentModule
+	v.currentModule = name
+	v.pushFrame(Frame{Module: name})
+	_, err := v.evalScript(NewContext(state), body)
+	v.popFrame()
+	v.currentModule = parent
+	if err != nil {
+		delete(v.modules, name)
+		return nil, err
+	}
+	return module, nil
+}
+
+// GShellExport makes the given functions of the current
+// module available to other modules
+func GShellExport(c *CallStack) {
+	if c.VM.currentModule == mainModuleSym {
+		c.FailWith = errors.New("export can only be used inside a module")
+		return
+	}
+	module := c.VM.modules[c.VM.currentModule]
+	for _, a := range c.RawArgs {
+		name, ok := a.(ast.Symbol)
+		if !ok {
+			c.FailWith = errors.New("export <function-name>...")
+			return
+		}
+		if _, defined := module.function(name); !defined {
+			c.FailWith = fmt.Errorf("Module %v does not contain a function called %v", c.VM.currentModule, name)
+			return
+		}
+		module.exports[name] = true
+	}
+	c.ReturnValue = trueSym
+}
+
+// splitQualified splits module/name symbols
+func splitQualified(sym ast.Symbol) (module, name ast.Symbol, ok bool) {
+	parts := strings.SplitN(sym.Text(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ast.Symbol{}, ast.Symbol{}, false
+	}
+	var err error
+	if module, err = ast.NewSymbol(parts[0]); err != nil {
+		return ast.Symbol{}, ast.Symbol{}, false
+	}
+	if name, err = ast.NewSymbol(parts[1]); err != nil {
+		return ast.Symbol{}, ast.Symbol{}, false
+	}
+	return module, name, true
+}
+
+func (v *VM) exportedFunction(moduleName, name ast.Symbol) (*function, error) {
+	module := v.modules[moduleName]
+	if module == nil {
+		return nil, fmt.Errorf("Module %v not found", moduleName)
+	}
+	fn, found := module.function(name)
+	if !found {
+		return nil, fmt.Errorf("Module %v does not contain a function called %v", moduleName, name)
+	}
+	if !module.exports[name] {
+		return nil, fmt.Errorf("Function %v is not exported by module %v", name, moduleName)
+	}
+	return fn, nil
+}
+
+// function returns the function called name defined by the module
+// itself, names from the root context are not considered
+func (m *Module) function(name ast.Symbol) (*function, bool) {
+	fn, ok := m.definitions.refs[name].(*function)
+	return fn, ok
+}
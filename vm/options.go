@@ -0,0 +1,110 @@
+package vm
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// Option changes the configuration of a VM created by NewVM
+	Option func(*VM)
+)
+
+const (
+	// SearchPathEnv is the environment variable with the list of
+	// directories searched by import, after the ones given to
+	// WithSearchPath
+	SearchPathEnv = "GSHELL_PATH"
+
+	// DefaultMaxCallDepth is the number of nested function
+	// calls allowed by a VM, tail calls do not count
+	DefaultMaxCallDepth = 1000
+)
+
+// WithSearchPath adds directories to the list
+// of places where import looks for modules
+func WithSearchPath(dirs ...string) Option {
+	return func(v *VM) {
+		v.searchPath = append(v.searchPath, dirs...)
+	}
+}
+
+// WithMaxCallDepth changes the number of nested function calls
+// allowed before the VM fails with a stack overflow, a value
+// less than or equal to zero removes the limit
+func WithMaxCallDepth(depth int) Option {
+	return func(v *VM) {
+		v.maxCallDepth = depth
+	}
+}
+
+// WithMaxCommands limits the number of commands executed by each run,
+// every iteration of loop counts as a command
+func WithMaxCommands(n int64) Option {
+	return func(v *VM) {
+		v.budget.maxCommands = n
+	}
+}
+
+// WithMaxListSize limits the number of items of the lists
+// and the number of entries of the maps built by the script
+func WithMaxListSize(n int) Option {
+	return func(v *VM) {
+		v.budget.maxListSize = n
+	}
+}
+
+// WithWallClockLimit limits how long each run might take, it is
+// checked between commands so a builtin blocked for a long time
+// should rely on RunContext instead
+func WithWallClockLimit(d time.Duration) Option {
+	return func(v *VM) {
+		v.budget.wallClock = d
+	}
+}
+
+// WithBuiltins only allows scripts to use the given builtins,
+// calling any other builtin fails with a PermissionError.
+//
+// Names which are not builtins make every run fail, so typos
+// do not go unnoticed
+func WithBuiltins(names ...ast.Symbol) Option {
+	return func(v *VM) {
+		if v.allowed == nil {
+			v.allowed = make(map[ast.Symbol]struct{}, len(names))
+		}
+		for _, n := range names {
+			v.allowed[n] = struct{}{}
+		}
+	}
+}
+
+// WithoutCapabilities denies every builtin which requires
+// one of the given capabilities
+func WithoutCapabilities(caps ...Capability) Option {
+	return func(v *VM) {
+		for _, c := range caps {
+			v.deniedCaps[c] = true
+		}
+	}
+}
+
+// Sandboxed denies access to the filesystem, to other processes
+// and to the network, it is meant for scripts which are not trusted
+func Sandboxed() Option {
+	return WithoutCapabilities(CapFilesystem, CapProcess, CapNetwork)
+}
+
+// defaultSearchPath returns the directories listed in SearchPathEnv
+func defaultSearchPath() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(SearchPathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
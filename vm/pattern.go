@@ -0,0 +1,95 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/andrebq/gshell/ast"
+)
+
+type (
+	// binding is a variable extracted from a pattern
+	binding struct {
+		name  ast.Symbol
+		value Value
+	}
+)
+
+// checkPattern validates the patterns accepted by let and by the
+// parameters of a function: a variable or a list of patterns where
+// the last item might be the spread of a variable ($rest...)
+func checkPattern(pattern ast.Argument) error {
+	seen := make(map[ast.Symbol]bool)
+	var check func(ast.Argument) error
+	check = func(pattern ast.Argument) error {
+		switch pattern := pattern.(type) {
+		case ast.Var:
+			if seen[pattern.Name()] {
+				return fmt.Errorf("Variable %v is used more than once in the pattern", pattern)
+			}
+			seen[pattern.Name()] = true
+			return nil
+		case *ast.List:
+			var err error
+			items := pattern.ToSlice(nil)
+			for i, item := range items {
+				if spread, ok := item.(*ast.Spread); ok {
+					if _, isVar := spread.Value().(ast.Var); !isVar || i != len(items)-1 {
+						return fmt.Errorf("Only the last item of a pattern can be the spread of a variable got %v", pattern)
+					}
+					item = spread.Value()
+				}
+				if err = check(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return fmt.Errorf("Invalid pattern %v, expecting a variable or a list of patterns", pattern)
+	}
+	return check(pattern)
+}
+
+// destructure matches value against pattern and returns the
+// variables it binds, pattern must be valid according to checkPattern
+func destructure(pattern ast.Argument, value Value) ([]binding, error) {
+	var out []binding
+	var match func(pattern ast.Argument, value Value) error
+	match = func(pattern ast.Argument, value Value) error {
+		switch pattern := pattern.(type) {
+		case ast.Var:
+			out = append(out, binding{name: pattern.Name(), value: value})
+			return nil
+		case *ast.List:
+			lst, ok := value.(*ast.List)
+			if !ok {
+				return fmt.Errorf("Pattern %v expects a list got %v", pattern, render(value))
+			}
+			patterns := pattern.ToSlice(nil)
+			items := lst.ToSlice(nil)
+			var rest *ast.Spread
+			if len(patterns) > 0 {
+				rest, _ = patterns[len(patterns)-1].(*ast.Spread)
+			}
+			switch {
+			case rest == nil && len(items) != len(patterns):
+				return fmt.Errorf("Pattern %v expects a list with %v items got %v", pattern, len(patterns), render(value))
+			case rest != nil && len(items) < len(patterns)-1:
+				return fmt.Errorf("Pattern %v expects a list with at least %v items got %v", pattern, len(patterns)-1, render(value))
+			}
+			if rest != nil {
+				patterns = patterns[:len(patterns)-1]
+			}
+			for i, p := range patterns {
+				if err := match(p, items[i]); err != nil {
+					return err
+				}
+			}
+			if rest != nil {
+				return match(rest.Value(), ast.NilList().Append(items[len(patterns):]...))
+			}
+			return nil
+		}
+		return fmt.Errorf("Invalid pattern %v", pattern)
+	}
+	return out, match(pattern, value)
+}
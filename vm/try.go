@@ -0,0 +1,140 @@
+package vm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andrebq/gshell/ast"
+	"github.com/andrebq/gshell/ast/match"
+)
+
+const (
+	// kinds of the errors caught by try
+	userErrorKind       = "user"
+	undefinedErrorKind  = "undefined-variable"
+	runtimeErrorKind    = "runtime"
+	timeoutErrorKind    = "timeout"
+	permissionErrorKind = "permission"
+)
+
+// GShellTry evaluates a block and hands its error (if any) to the
+// catch block as a map with the message, kind and the command
+// which failed, the finally block runs regardless of the outcome
+func GShellTry(c *CallStack) {
+	tryUsage := "try <{body}> [catch <$variable> <{handler}>] [finally <{cleanup}>]"
+	var body, handler, cleanup *ast.Script
+	var errVar ast.Var
+	if !match.Apply(&c.RawArgs, match.Script(&body)) {
+		c.FailWith = errors.New(tryUsage)
+		return
+	}
+	if match.Apply(&c.RawArgs, match.Symbol(catchSym)) {
+		var ok bool
+		if len(c.RawArgs) > 0 {
+			errVar, ok = c.RawArgs[0].(ast.Var)
+			c.RawArgs = c.RawArgs[1:]
+		}
+		if !ok || !match.Apply(&c.RawArgs, match.Script(&handler)) {
+			c.FailWith = errors.New(tryUsage)
+			return
+		}
+	}
+	if match.Apply(&c.RawArgs, match.Symbol(finallySym)) && !match.Apply(&c.RawArgs, match.Script(&cleanup)) {
+		c.FailWith = errors.New(tryUsage)
+		return
+	}
+	if len(c.RawArgs) != 0 || (handler == nil && cleanup == nil) {
+		c.FailWith = errors.New(tryUsage)
+		return
+	}
+
+	c.ReturnValue, c.FailWith = c.VM.evalScript(c.Context, body)
+	// once the script is cancelled it must stop, so
+	// errors are not caught after that
+	if c.FailWith != nil && handler != nil && catchable(c.FailWith) && c.Context.goCtx.Err() == nil {
+		ctx := NewContext(c.Context)
+		ctx.Set(errVar.Name(), errorValue(c.FailWith))
+		c.ReturnValue, c.FailWith = c.VM.evalScript(ctx, handler)
+	}
+	if cleanup == nil {
+		return
+	}
+	// an error from the cleanup replaces the one from the body
+	if _, err := c.VM.evalScript(NewContext(c.Context), cleanup); err != nil {
+		c.ReturnValue, c.FailWith = nil, err
+	}
+}
+
+// GShellFail raises an error which can be caught by try, the
+// argument is either a message or an error caught by try
+func GShellFail(c *CallStack) {
+	failUsage := "fail <message> [--kind=<kind>] or fail <$caught-error>"
+	var kindArg ast.Argument
+	if len(c.RawArgs) != 1 || !match.ApplyFlags(&c.RawFlags, match.Flags(match.OptionalFlag(kindSym, &kindArg), match.NoFlags())) {
+		c.FailWith = errors.New(failUsage)
+		return
+	}
+	value, err := c.VM.Eval(c.Context, c.RawArgs[0])
+	if err != nil {
+		c.FailWith = err
+		return
+	}
+	f := &failure{kind: userErrorKind}
+	if caught, ok := value.(*ast.Map); ok {
+		message, _ := caught.Get(messageSym)
+		kind, _ := caught.Get(kindSym)
+		if message == nil || kind == nil {
+			c.FailWith = fmt.Errorf("%v is not an error caught by try", caught)
+			return
+		}
+		f.message, f.kind = render(message), render(kind)
+	} else {
+		f.message = render(value)
+	}
+	if kindArg != nil {
+		kind, err := c.VM.Eval(c.Context, kindArg)
+		if err != nil {
+			c.FailWith = err
+			return
+		}
+		f.kind = render(kind)
+	}
+	c.FailWith = f
+}
+
+// catchable returns false for errors used to control the flow
+// of the script and for budget errors, they must reach the code
+// handling them
+func catchable(err error) bool {
+	var ret *returnSignal
+	return !errors.As(err, &ret) && !errors.Is(err, ErrBudgetExceeded)
+}
+
+// errorValue converts err to the map given to catch blocks
+func errorValue(err error) *ast.Map {
+	message := err.Error()
+	var command string
+	var vmErr *Error
+	if errors.As(err, &vmErr) {
+		message = vmErr.Err.Error()
+		command = vmErr.Command.Text()
+	}
+	kind := runtimeErrorKind
+	var f *failure
+	var undefined *undefinedVariableError
+	switch {
+	case errors.As(err, &f):
+		kind = f.kind
+	case errors.As(err, &undefined):
+		kind = undefinedErrorKind
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = timeoutErrorKind
+	case errors.Is(err, ErrPermissionDenied):
+		kind = permissionErrorKind
+	}
+	return ast.NilMap().
+		Set(messageSym, ast.NewText(message)).
+		Set(kindSym, ast.NewText(kind)).
+		Set(commandSym, ast.NewText(command))
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
 	"sync"
 
 	"github.com/andrebq/gshell/ast"
+	"github.com/andrebq/gshell/ast/match"
 	"github.com/andrebq/gshell/internal/parser"
 	"github.com/andrebq/gshell/mailbox"
 )
@@ -27,6 +31,35 @@ type (
 		stdin  *Actor
 
 		pids map[ast.Symbol]*Actor
+
+		// searchPath lists the directories used by import
+		searchPath []string
+		// imports caches the modules imported from each file
+		imports map[string]*Module
+		// importing holds the files being imported, in order,
+		// and is used to detect cycles
+		importing []string
+
+		// frames holds the functions being called, the
+		// innermost call is the last one
+		frames []Frame
+		// depth is the number of nested function calls
+		depth        int
+		maxCallDepth int
+
+		budget budget
+
+		// allowed lists the builtins given to WithBuiltins,
+		// nil allows all of them
+		allowed    map[ast.Symbol]struct{}
+		deniedCaps map[Capability]bool
+		// denied maps the builtins which are not allowed
+		// to the capability they require
+		denied map[ast.Symbol]Capability
+
+		// configErr is returned by every run when the
+		// options given to NewVM are not valid
+		configErr error
 	}
 
 	Actor struct {
@@ -37,6 +70,9 @@ type (
 
 	Module struct {
 		definitions *Context
+		// exports lists the functions which can be called
+		// from other modules (ie.: strings/upper)
+		exports map[ast.Symbol]bool
 	}
 
 	Value interface{}
@@ -48,14 +84,23 @@ type (
 
 	CallStack struct {
 		RawArgs []ast.Argument
-		VM      *VM
-		Context *Context
+		// RawFlags holds the flags given to the command,
+		// they are not included in RawArgs
+		RawFlags []ast.Flag
+		VM       *VM
+		Context  *Context
 
 		// Use to indcate that the function call failed
 		// and give it a reason
 		FailWith error
 
 		ReturnValue interface{}
+
+		// cmd is the command being executed, if any
+		cmd *ast.Cmd
+		// tail is set when cmd is the last command
+		// of the body of a function
+		tail bool
 	}
 
 	Context struct {
@@ -83,6 +128,21 @@ var (
 	toSym      = ast.MustNewSymbol("to")
 	loop       = ast.MustNewSymbol("loop")
 	funcSym    = ast.MustNewSymbol("func")
+	fnSym      = ast.MustNewSymbol("fn")
+	callSym    = ast.MustNewSymbol("call")
+	returnSym  = ast.MustNewSymbol("return")
+	moduleSym  = ast.MustNewSymbol("module")
+	exportSym  = ast.MustNewSymbol("export")
+	importSym  = ast.MustNewSymbol("import")
+	asSym      = ast.MustNewSymbol("as")
+	trySym     = ast.MustNewSymbol("try")
+	timeoutSym = ast.MustNewSymbol("timeout")
+	catchSym   = ast.MustNewSymbol("catch")
+	finallySym = ast.MustNewSymbol("finally")
+	failSym    = ast.MustNewSymbol("fail")
+	kindSym    = ast.MustNewSymbol("kind")
+	messageSym = ast.MustNewSymbol("message")
+	commandSym = ast.MustNewSymbol("command")
 
 	trueSym  = ast.MustNewSymbol("true")
 	falseSym = ast.MustNewSymbol("false")
@@ -113,6 +173,7 @@ func mustSym(s string) ast.Symbol {
 func EmptyModule(ctx *Context) *Module {
 	return &Module{
 		definitions: NewContext(ctx),
+		exports:     make(map[ast.Symbol]bool),
 	}
 }
 
@@ -127,7 +188,7 @@ func newRootContext() *Context {
 	return rootCtx
 }
 
-func NewVM() *VM {
+func NewVM(opts ...Option) *VM {
 	rootCtx := newRootContext()
 
 	vm := &VM{
@@ -138,15 +199,17 @@ func NewVM() *VM {
 		rootCtx:       rootCtx,
 		currentModule: mainModuleSym,
 		pids:          make(map[ast.Symbol]*Actor),
+		imports:       make(map[string]*Module),
+		maxCallDepth:  DefaultMaxCallDepth,
+		deniedCaps:    make(map[Capability]bool),
+		denied:        make(map[ast.Symbol]Capability),
 	}
-	vm.builtins[printlnSym] = ProcessFunc(GShellPrintln)
-	vm.builtins[letSym] = ProcessFunc(GShellLetVariable)
-	vm.builtins[switchSym] = ProcessFunc(GShellSwitch)
-	vm.builtins[trueSym] = MakeIdentityProcess(trueSym)
-	vm.builtins[falseSym] = MakeIdentityProcess(falseSym)
-	vm.builtins[guardSym] = ProcessFunc(GShellGuard)
-	vm.builtins[loop] = ProcessFunc(GShellLoop)
-	vm.builtins[funcSym] = ProcessFunc(GShellFunc)
+
+	for _, opt := range opts {
+		opt(vm)
+	}
+	vm.registerBuiltins(defaultBuiltins())
+	vm.searchPath = append(vm.searchPath, defaultSearchPath()...)
 
 	vm.stdout = vm.newActor(localSym, stdoutSym)
 	vm.stderr = vm.newActor(localSym, stderrSym)
@@ -155,6 +218,20 @@ func NewVM() *VM {
 	return vm
 }
 
+// rejectFlags wraps a builtin which does not use flags, so it fails
+// when called with any, like user functions do for the flags they
+// do not declare
+func rejectFlags(name ast.Symbol, p Process) Process {
+	return ProcessFunc(func(c *CallStack) {
+		flags := c.RawFlags
+		if !match.ApplyFlags(&flags, match.NoFlags()) {
+			c.FailWith = fmt.Errorf("Command %v does not accept the flag %v", name, flags[0].Name())
+			return
+		}
+		p.Run(c)
+	})
+}
+
 func (v *VM) newActor(scope ast.Symbol, id ast.Symbol) *Actor {
 	upid := ast.ScopedSymbol(scope, id)
 	actor := v.pids[upid]
@@ -177,41 +254,170 @@ func (v *VM) Stderr() mailbox.Reader {
 	return v.stderr.box.Reader()
 }
 
+// Run evaluates a script block (code wrapped in '{' '}')
 func (v *VM) Run(code string) (interface{}, error) {
+	return v.RunContext(context.Background(), code)
+}
+
+// RunContext works like Run but stops the script with the error
+// from ctx once it is cancelled or its deadline expires
+func (v *VM) RunContext(ctx context.Context, code string) (interface{}, error) {
 	ast, err := parser.Parse(code)
 	if err != nil {
 		return nil, err
 	}
+	return v.runAst(ctx, ast)
+}
+
+// RunFile evaluates the program in the given file, top-level
+// commands in a program are not wrapped in a block
+func (v *VM) RunFile(path string) (interface{}, error) {
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return v.runProgram(path, string(code))
+}
+
+// RunReader evaluates the program read from r, like RunFile
+func (v *VM) RunReader(r io.Reader) (interface{}, error) {
+	code, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return v.runProgram("", string(code))
+}
+
+func (v *VM) runProgram(source, code string) (interface{}, error) {
+	ast, err := parser.ParseProgram(source, code)
+	if err != nil {
+		return nil, err
+	}
+	return v.runAst(context.Background(), ast)
+}
+
+func (v *VM) runAst(goCtx context.Context, tree *ast.Ast) (interface{}, error) {
+	if v.configErr != nil {
+		return nil, v.configErr
+	}
 	ctx := NewContext(v.rootCtx)
-	return v.evalScript(ctx, ast.Root())
+	ctx.goCtx = goCtx
+	v.pushFrame(Frame{Module: v.currentModule})
+	defer v.popFrame()
+	v.budget.start()
+	value, err := v.evalScript(ctx, tree.Root())
+	return value, asError(err)
 }
 
 func (v *VM) evalList(ctx *Context, lst *ast.List) (*ast.List, error) {
 	output := ast.NilList()
 	var err error
 	lst.ForEach(func(a ast.Argument) bool {
+		if spread, ok := a.(*ast.Spread); ok {
+			var items *ast.List
+			items, err = v.evalSpread(ctx, spread)
+			if err != nil {
+				return false
+			}
+			output = output.Append(items.ToSlice(nil)...)
+			err = v.budget.checkList(output.Len())
+			return err == nil
+		}
 		var value Value
 		value, err = v.Eval(ctx, a)
 		if err != nil {
 			return false
 		}
 		var arg ast.Argument
-		arg, err = ast.EncodeValue(value)
+		arg, err = encodeValue(value)
 		if err != nil {
 			return false
 		}
 		// this is very slow but I'm too lazy
 		// to use a slice
 		output = output.Append(arg)
-		return true
+		err = v.budget.checkList(output.Len())
+		return err == nil
 	})
 	return output, err
 }
 
+func (v *VM) evalMap(ctx *Context, m *ast.Map) (*ast.Map, error) {
+	if err := v.budget.checkList(m.Len()); err != nil {
+		return nil, err
+	}
+	output := make(map[ast.Argument]ast.Argument, m.Len())
+	var err error
+	m.ForEach(func(k, val ast.Argument) bool {
+		var key, value ast.Argument
+		key, err = v.evalArgument(ctx, k)
+		if err != nil {
+			return false
+		}
+		switch key.(type) {
+		case ast.Symbol, ast.Number, ast.Text:
+		default:
+			err = fmt.Errorf("map keys must be symbols, numbers or text got %T", key)
+			return false
+		}
+		value, err = v.evalArgument(ctx, val)
+		if err != nil {
+			return false
+		}
+		output[key] = value
+		return true
+	})
+	return ast.NewMap(output), err
+}
+
+// evalArgument works like Eval but encodes the result as an ast.Argument
+func (v *VM) evalArgument(ctx *Context, a ast.Argument) (ast.Argument, error) {
+	value, err := v.Eval(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	return encodeValue(value)
+}
+
+// encodeValue converts value to an argument, functions are
+// wrapped in an ast.Opaque so lists and maps can hold them
+func encodeValue(value Value) (ast.Argument, error) {
+	if fn, ok := value.(*function); ok {
+		return ast.NewOpaque(fn), nil
+	}
+	return ast.EncodeValue(value)
+}
+
+func (v *VM) evalTemplate(ctx *Context, tmpl *ast.Template) (string, error) {
+	var buf strings.Builder
+	for _, part := range tmpl.Parts() {
+		value, err := v.Eval(ctx, part)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(render(value))
+	}
+	return buf.String(), nil
+}
+
 func (v *VM) evalScript(ctx *Context, sc *ast.Script) (Value, error) {
+	return v.evalBlock(ctx, sc, false)
+}
+
+// evalBlock evaluates the commands of sc, when tail is true the
+// last command is in tail position of a function body and calls
+// to other functions are returned as a *tailCall
+func (v *VM) evalBlock(ctx *Context, sc *ast.Script, tail bool) (Value, error) {
 	var lastReturn Value
-	for _, c := range sc.Commands() {
-		call := v.runCommand(ctx, c)
+	cmds := sc.Commands()
+	for i, c := range cmds {
+		if err := ctx.goCtx.Err(); err != nil {
+			return nil, v.withSpan(c, err)
+		}
+		if err := v.budget.spend(); err != nil {
+			return nil, v.withSpan(c, err)
+		}
+		call := v.runCommand(ctx, c, tail && i == len(cmds)-1)
 		lastReturn = call.ReturnValue
 		if call.FailWith != nil {
 			return nil, call.FailWith
@@ -220,54 +426,248 @@ func (v *VM) evalScript(ctx *Context, sc *ast.Script) (Value, error) {
 	return lastReturn, nil
 }
 
-func (v *VM) runCommand(ctx *Context, cmd *ast.Cmd) *CallStack {
+func (v *VM) runCommand(ctx *Context, cmd *ast.Cmd, tail bool) *CallStack {
 	call := &CallStack{
-		VM:      v,
-		RawArgs: cmd.Arguments(),
-		Context: ctx,
+		VM:       v,
+		RawFlags: cmd.Flags(),
+		Context:  ctx,
+		cmd:      cmd,
+		tail:     tail,
+	}
+	top := len(v.frames) - 1
+	var caller Frame
+	if top >= 0 {
+		caller = v.frames[top]
+		v.frames[top].Command, v.frames[top].Span = commandName(cmd), cmd.Span()
+	}
+	call.RawArgs, call.FailWith = v.expandSpreads(ctx, cmd.Arguments())
+	if call.FailWith == nil {
+		v.dispatch(call, cmd)
+	}
+	if _, isTail := call.FailWith.(*tailCall); call.FailWith != nil && !isTail {
+		call.FailWith = v.withSpan(cmd, call.FailWith)
+	}
+	if top >= 0 {
+		v.frames[top].Command, v.frames[top].Span = caller.Command, caller.Span
+	}
+	return call
+}
+
+// expandSpreads replaces the spread arguments by the items of their lists,
+// so commands receive them as separate arguments
+func (v *VM) expandSpreads(ctx *Context, args []ast.Argument) ([]ast.Argument, error) {
+	var out []ast.Argument
+	for i, a := range args {
+		spread, ok := a.(*ast.Spread)
+		if !ok {
+			if out != nil {
+				out = append(out, a)
+			}
+			continue
+		}
+		if out == nil {
+			out = append(make([]ast.Argument, 0, len(args)), args[:i]...)
+		}
+		items, err := v.evalSpread(ctx, spread)
+		if err != nil {
+			return nil, err
+		}
+		out = items.ToSlice(out)
+	}
+	if out == nil {
+		return args, nil
+	}
+	return out, nil
+}
+
+func (v *VM) evalSpread(ctx *Context, spread *ast.Spread) (*ast.List, error) {
+	value, err := v.Eval(ctx, spread.Value())
+	if err != nil {
+		return nil, err
+	}
+	lst, ok := value.(*ast.List)
+	if !ok {
+		return nil, fmt.Errorf("Cannot spread %v, only lists can be spread", render(value))
+	}
+	return lst, nil
+}
+
+func (v *VM) dispatch(call *CallStack, cmd *ast.Cmd) {
+	if expr, isExpr := cmd.Expression(); isExpr {
+		call.ReturnValue, call.FailWith = v.Eval(call.Context, expr)
+		return
+	}
+	if fn, isVar := cmd.CommandVar(); isVar {
+		value, found := call.Context.Get(fn.Name())
+		if !found {
+			call.FailWith = &undefinedVariableError{v: fn}
+			return
+		}
+		v.callValue(call, value)
+		return
 	}
 	bt, found := v.builtins[cmd.Command()]
 	if found {
 		v.callBuiltin(call, bt)
-		return call
+		return
 	}
-	value, found := ctx.Get(cmd.Command())
+	if capability, denied := v.denied[cmd.Command()]; denied {
+		call.FailWith = &PermissionError{Command: cmd.Command(), Capability: capability}
+		return
+	}
+	value, found := call.Context.Get(cmd.Command())
 	if found {
 		v.callValue(call, value)
-		return call
+		return
 	}
 
-	moduleFunc, found := v.modules[v.currentModule].definitions.Get(cmd.Command())
+	moduleFunc, found := v.modules[v.currentModule].function(cmd.Command())
 	if found {
-		v.callFunction(call, moduleFunc.(*function))
-		return call
+		v.callDefined(call, moduleFunc)
+		return
+	}
+
+	if module, name, qualified := splitQualified(cmd.Command()); qualified {
+		fn, err := v.exportedFunction(module, name)
+		if err != nil {
+			call.FailWith = err
+			return
+		}
+		v.callDefined(call, fn)
+		return
 	}
 
 	call.FailWith = fmt.Errorf("Command %v not found", cmd.Command().Text())
-	return call
 }
 
-func (v *VM) callFunction(call *CallStack, funcDeclaration *function) {
-	ctx := NewFunctionContext(funcDeclaration.upvalues)
-	if len(funcDeclaration.args) != len(call.RawArgs) {
-		call.FailWith = fmt.Errorf("Function %v requires %v args got %v", funcDeclaration.name, len(funcDeclaration.args), len(call.RawArgs))
+// callFunction runs fn with the arguments of call, tail calls made
+// by the body are executed by the same loop so functions can recurse
+// without growing the Go stack
+func (v *VM) callFunction(call *CallStack, fn *function) {
+	if v.maxCallDepth > 0 && v.depth >= v.maxCallDepth {
+		call.FailWith = newStackOverflowError(v.depth, v.trace())
 		return
 	}
+	v.depth++
+	v.pushFrame(newFrame(fn))
+	defer func() {
+		v.depth--
+		v.popFrame()
+	}()
+
+	caller := v.currentModule
+	defer func() { v.currentModule = caller }()
+
+	args := call
+	for {
+		value, err := v.runFunction(args, fn)
+		if tail, ok := err.(*tailCall); ok {
+			// the frame of the caller is replaced by the function
+			// it calls since its body has nothing left to do, the
+			// arguments still belong to the module of the caller
+			fn, args = tail.fn, tail.call
+			v.currentModule = tail.module
+			top := len(v.frames) - 1
+			v.frames[top] = v.frames[top].tailCall(fn)
+			continue
+		}
+		var ret *returnSignal
+		switch {
+		case errors.As(err, &ret):
+			call.ReturnValue = ret.value
+		case err != nil && args != call:
+			call.FailWith = v.withSpan(args.cmd, err)
+		case err != nil:
+			call.FailWith = err
+		default:
+			call.ReturnValue = value
+		}
+		return
+	}
+}
+
+// runFunction binds the arguments and flags given to call
+// and evaluates the body of fn
+func (v *VM) runFunction(call *CallStack, fn *function) (Value, error) {
+	ctx := NewFunctionContext(fn.upvalues)
+	// the function runs under the Go context of its
+	// caller, not the one where it was defined
+	ctx.goCtx = call.Context.goCtx
+	if len(fn.args) != len(call.RawArgs) {
+		return nil, fmt.Errorf("Function %v requires %v args got %v", fn.name, len(fn.args), len(call.RawArgs))
+	}
 	for i := range call.RawArgs {
-		var err error
-		var argValue Value
-		argValue, err = v.Eval(call.Context, call.RawArgs[i])
+		argValue, err := v.Eval(call.Context, call.RawArgs[i])
 		if err != nil {
-			call.FailWith = err
-			return
+			return nil, err
+		}
+		bindings, err := destructure(fn.args[i], argValue)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range bindings {
+			ctx.Set(b.name, b.value)
 		}
-		ctx.Set(funcDeclaration.args[i].Name(), argValue)
 	}
-	v.evalScript(ctx, funcDeclaration.body)
+	given := make(map[ast.Symbol]ast.Flag, len(call.RawFlags))
+	for _, f := range call.RawFlags {
+		if _, declared := fn.flags.Get(f.Name()); !declared {
+			return nil, fmt.Errorf("Function %v does not accept the flag %v", fn.name, f.Name())
+		}
+		given[f.Name()] = f
+	}
+	var err error
+	fn.flags.ForEach(func(name, value ast.Argument) bool {
+		if f, ok := given[name.(ast.Symbol)]; ok {
+			var flagValue Value
+			flagValue, err = v.EvalFlag(call.Context, f)
+			ctx.Set(f.Name(), flagValue)
+			return err == nil
+		}
+		ctx.Set(name.(ast.Symbol), value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	// commands inside the function are resolved by the module
+	// which defined it
+	caller := v.currentModule
+	v.currentModule = fn.module
+	value, err := v.evalBlock(ctx, fn.body, true)
+	v.currentModule = caller
+
+	switch err.(type) {
+	case nil, *tailCall, *returnSignal:
+		return value, err
+	}
+	if errors.Is(err, ErrBudgetExceeded) {
+		// budget errors usually happen deep inside recursive calls,
+		// the trace already lists the frames so wrapping the error
+		// for every one of them would only add noise
+		return nil, err
+	}
+	return nil, fmt.Errorf("Function %v failed: %w", fn.name, err)
+}
+
+// callDefined calls a function defined by the script, when the command
+// is the last one of a function body the call is returned to callFunction
+// instead of nesting another call
+func (v *VM) callDefined(call *CallStack, fn *function) {
+	if call.tail {
+		call.FailWith = &tailCall{fn: fn, call: call, module: v.currentModule}
+		return
+	}
+	v.callFunction(call, fn)
 }
 
 func (v *VM) callValue(call *CallStack, value Value) {
 	switch value := value.(type) {
+	case *function:
+		v.callDefined(call, value)
+	case *ast.Opaque:
+		// functions taken from a list or map
+		v.callValue(call, value.Value())
 	case Process:
 		value.Run(call)
 	default:
@@ -307,17 +707,44 @@ func (v *VM) Eval(ctx *Context, a ast.Argument) (interface{}, error) {
 	case ast.Var:
 		v, ok := ctx.Get(a.Name())
 		if !ok {
-			return nil, fmt.Errorf("Variable %v is not defined", a)
+			return nil, &undefinedVariableError{v: a}
 		}
 		return v, nil
 	case *ast.Script:
 		return v.evalScript(ctx, a)
 	case *ast.List:
 		return v.evalList(ctx, a)
+	case *ast.Map:
+		return v.evalMap(ctx, a)
+	case *ast.Template:
+		return v.evalTemplate(ctx, a)
+	case *ast.Substitution:
+		call := v.runCommand(ctx, a.Command(), false)
+		if call.FailWith != nil {
+			return nil, call.FailWith
+		}
+		return call.ReturnValue, nil
+	case *ast.Expression:
+		return v.Eval(ctx, a.Value())
+	case *ast.Binary:
+		return v.evalBinary(ctx, a)
+	case *ast.Unary:
+		return v.evalUnary(ctx, a)
+	case *ast.Opaque:
+		return a.Value(), nil
 	}
 	return nil, fmt.Errorf("cannot decode %T into a meangingful value", a)
 }
 
+// EvalFlag returns the value of the flag, flags
+// without a value are evaluated as true
+func (v *VM) EvalFlag(ctx *Context, f ast.Flag) (Value, error) {
+	if f.Value() == nil {
+		return trueSym, nil
+	}
+	return v.Eval(ctx, f.Value())
+}
+
 func (v *VM) CastTo(ctx *Context, input interface{}, out interface{}) error {
 	switch out := out.(type) {
 	case *bool:
@@ -368,16 +795,13 @@ func (v *VM) castToFloat(ctx *Context, input interface{}, out *float64) error {
 	return nil
 }
 
-func (v *VM) newFunction(ctx *Context, module, name ast.Symbol, args *ast.List, script *ast.Script) *function {
-	var items []ast.Var
-	args.ForEach(func(a ast.Argument) bool {
-		items = append(items, a.(ast.Var))
-		return true
-	})
+func (v *VM) newFunction(ctx *Context, module, name ast.Symbol, args []ast.Argument, flags *ast.Map, script *ast.Script) *function {
 	return &function{
 		module:   module,
+		name:     name,
 		upvalues: ctx,
-		args:     items,
+		args:     args,
+		flags:    flags,
 		body:     script,
 	}
 }
@@ -1,10 +1,19 @@
 package vm
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/andrebq/gshell/ast"
 	"github.com/andrebq/gshell/mailbox"
 )
 
@@ -22,6 +31,132 @@ func TestVM(t *testing.T) {
 	})
 }
 
+func TestStringLiterals(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{ println "hello, world!" 'C:\some path'; }`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello, world! C:\\some path\n",
+	})
+}
+
+func TestStringInterpolation(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		let $name bob
+		let $count 3
+		println "user $name has ${count} items, ${ true }!"
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, vm.Stdout(), []Value{
+		"user bob has 3 items, true!\n",
+	})
+}
+
+func TestCommentsAreIgnored(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run("# header\n{\n\t# leading\n\tprintln hello #| block |# world # trailing\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello world\n",
+	})
+}
+
+func TestCommandSubstitution(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		let $flag (switch {
+			case { false } { false }
+			else { true }
+		})
+		println (true) $flag [ (false) ] "${ true }"
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertOutput(t, vm.Stdout(), []Value{
+		"true true [ false ] true\n",
+	})
+}
+
+func TestExpressions(t *testing.T) {
+	for _, c := range []struct {
+		code     string
+		expected Value
+	}{
+		{"{ let $a 3; let $r (= $a * 2 + 1) }", ast.NewNumber(7)},
+		{"{ let $r (= 1 + 2 * 3 - 4 / 2) }", ast.NewNumber(5)},
+		{"{ let $r (= (1 + 2) * -3 % 5) }", ast.NewNumber(-4)},
+		{"{ let $r (= 1 - 2 - 3) }", ast.NewNumber(-4)},
+		{"{ let $r (= 1 < 2 and 2 <= 2) }", trueSym},
+		{"{ let $r (= not 1 == 1 or 3 > 4) }", falseSym},
+		{`{ let $r (= "a" < "b" and "x" == 'x' and 1 != "1") }`, trueSym},
+		{"{ let $r (= false and $undefined) }", falseSym},
+		{"{ guard { let $r (= 10 >= 5) } { yes } }", ast.MustNewSymbol("yes")},
+		{"{ guard { (= 10 >= 5) } { yes } }", ast.MustNewSymbol("yes")},
+		{"{ switch { case { (= 1 > 2) } { no }; else { (= 2 * 21) } } }", ast.NewNumber(42)},
+	} {
+		vm := NewVM()
+		vm.builtins[ast.MustNewSymbol("yes")] = MakeIdentityProcess(ast.MustNewSymbol("yes"))
+		value, err := vm.Run(c.code)
+		if err != nil {
+			t.Errorf("Code %q failed: %v", c.code, err)
+			continue
+		}
+		if !reflect.DeepEqual(value, c.expected) {
+			t.Errorf("Code %q should return %#v got %#v", c.code, c.expected, value)
+		}
+	}
+
+	for _, code := range []string{
+		"{ let $r (= 1 / 0) }",
+		"{ let $r (= 1 + true) }",
+		"{ let $r (= 1 < [ 1 ]) }",
+		"{ let $r (= not 1) }",
+	} {
+		if _, err := NewVM().Run(code); err == nil {
+			t.Errorf("Code %q should fail", code)
+		}
+	}
+}
+
+func TestMapLiterals(t *testing.T) {
+	vm := NewVM()
+	value, err := vm.Run(`{
+		let $key port
+		let $config %[ name "x" $key (= 8000 + 80) tags [ a $key ] ]
+		println $config
+		let $result $config
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, ok := value.(*ast.Map)
+	if !ok {
+		t.Fatalf("Expecting a map got %#v", value)
+	}
+	if port, _ := m.Get(ast.MustNewSymbol("port")); port != ast.NewNumber(8080) {
+		t.Errorf("Key should be evaluated got %v", port)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"%[ name \"x\" port 8080 tags [ a port ] ]\n",
+	})
+
+	if _, err := NewVM().Run("{ let $k [ a ]; let $m %[ $k 1 ] }"); err == nil {
+		t.Error("Lists should not be accepted as map keys")
+	}
+}
+
 func TestSetVariable(t *testing.T) {
 	vm := NewVM()
 	// no need to consume all the tokens from stdout
@@ -123,6 +258,335 @@ func TestFunctionDefinition(t *testing.T) {
 	}
 }
 
+func TestFlags(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		func greet [$name %[ greeting hello loud false ]] {
+			println $greeting $name $loud
+		}
+		greet bob
+		greet --greeting="good morning" alice --loud
+		greet --loud 1
+		loop i --to=2 --from=1 { println $i }
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello bob false\n",
+		"good morning alice true\n",
+		"hello 1 true\n",
+		"1\n",
+		"2\n",
+	})
+
+	for _, code := range []string{
+		"{ func greet [$name] { println $name }; greet bob --loud }",
+		"{ func greet [%[ loud false ] $name] { println $name } }",
+		"{ func greet [$name %[ 1 false ]] { println $name } }",
+		"{ loop i --from=1 --to=2 --step=1 { println $i } }",
+		"{ loop i --from 1 --to 2 { println $i } }",
+		"{ println a --x b }",
+		"{ let $a --x 1 }",
+		"{ true --x }",
+	} {
+		if _, err := NewVM().Run(code); err == nil {
+			t.Errorf("Code %q should fail", code)
+		}
+	}
+}
+
+func TestSpreadArguments(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		func add [$a $b $c] {
+			println (= $a + $b + $c)
+		}
+		let $args [ 1 2 ]
+		add $args... 3
+		add [ 10 $args... ]...
+		println [ 0 $args... [ 3 ] ]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"6\n",
+		"13\n",
+		"[ 0 1 2 [ 3 ] ]\n",
+	})
+
+	if _, err := NewVM().Run("{ let $a 1; println $a... }"); err == nil {
+		t.Error("Only lists should be spread")
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		let [$head $second $rest...] [ 1 2 3 4 ]
+		println $head $second $rest
+		func swap [[$a $b] $c] {
+			println $b $a $c
+		}
+		swap [ x [ y ] ] z
+		let [$only $empty...] [ 1 ]
+		println $only $empty
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"1 2 [ 3 4 ]\n",
+		"[ y ] x z\n",
+		"1 [ ]\n",
+	})
+
+	for _, c := range []struct {
+		code     string
+		expected string
+	}{
+		{"{ let [$a $b] [ 1 ] }", "Pattern [ $a $b ] expects a list with 2 items got [ 1 ]"},
+		{"{ let [$a $b $c...] [ 1 ] }", "Pattern [ $a $b $c... ] expects a list with at least 2 items got [ 1 ]"},
+		{"{ let [$a [$b]] [ 1 2 ] }", "Pattern [ $b ] expects a list got 2"},
+		{"{ func f [[$a]] { println $a }; f 1 }", "Pattern [ $a ] expects a list got 1"},
+		{"{ let [$a $a] [ 1 2 ] }", "Invalid use of 'let'"},
+		{"{ let [$a... $b] [ 1 2 ] }", "Invalid use of 'let'"},
+		{"{ func f [[$a 1]] { println $a } }", "Invalid pattern 1"},
+	} {
+		_, err := NewVM().Run(c.code)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("Code %q should fail with %q got %v", c.code, c.expected, err)
+		}
+	}
+}
+
+func TestAnonymousFunctions(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		let $greeting hello
+		let $greet (fn [$name] { println $greeting $name })
+		$greet bob
+		call $greet alice
+		func apply [$f $value] {
+			$f $value
+		}
+		apply $greet carol
+		func make-adder [$n] {
+			fn [$x] { (= $x + $n) }
+		}
+		let $add2 (make-adder 2)
+		println (call $add2 40) ($add2 1)
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello bob\n",
+		"hello alice\n",
+		"hello carol\n",
+		"42 3\n",
+	})
+
+	vm = NewVM()
+	_, err = vm.Run(`{
+		let $double (fn [$x] { println (= $x * 2) })
+		let [$f] [ $double ]
+		let $adders (loop i --from=1 --to=2 { fn [$x] { println (= $x + $i) } })
+		let [$add1 $add2] $adders
+		$f 4; $add1 10; $add2 10; call $add1 0
+		println %[ twice $double ]
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"8\n", "11\n", "12\n", "1\n",
+		"%[ twice <function fn> ]\n",
+	})
+
+	for _, code := range []string{
+		"{ $undefined 1 }",
+		"{ let $v 1; $v 2 }",
+		"{ call }",
+		"{ fn [$a] }",
+	} {
+		if _, err := NewVM().Run(code); err == nil {
+			t.Errorf("Code %q should fail", code)
+		}
+	}
+}
+
+func TestFunctionsReturnValues(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		func last [$a] {
+			println called
+			let $b (= $a * 2)
+		}
+		func find [$limit] {
+			loop i from 1 to 10 {
+				switch {
+					case { let $found (= $i * $i > $limit) } { return $i }
+				}
+			}
+			return
+		}
+		func nothing [] { return; println unreachable }
+		println (last 2) (find 20) (find 1000) (nothing)
+		let $early (fn [] { guard { true } { return early }; late })
+		println ($early)
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"called\n",
+		"4 5 <nil> <nil>\n",
+		"early\n",
+	})
+
+	_, err = NewVM().Run("{ func broken [] { missing-command }; broken }")
+	if err == nil || !strings.Contains(err.Error(), "Function broken failed: line 1:20: Command missing-command not found") {
+		t.Errorf("Errors inside functions should be reported got %v", err)
+	}
+	_, err = NewVM().Run("{ return 1 }")
+	if err == nil || !strings.Contains(err.Error(), "return used outside of a function") {
+		t.Errorf("Return should only be used inside functions got %v", err)
+	}
+}
+
+func TestModules(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.Run(`{
+		module greetings {
+			let $greeting hello
+			func prefix [$name] { let $out "$greeting $name" }
+			func greet [$name] { println (prefix $name) }
+			export greet
+		}
+		module other {
+			func greet [$name] { println other $name }
+			export greet
+		}
+		func greet [$name] { println main $name }
+		greetings/greet bob
+		other/greet bob
+		greet bob
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello bob\n",
+		"other bob\n",
+		"main bob\n",
+	})
+
+	for _, c := range []struct {
+		code     string
+		expected string
+	}{
+		{"{ module m { func f [] { true } }; m/f }", "Function f is not exported by module m"},
+		{"{ module m { export f }; m/f }", "Module m does not contain a function called f"},
+		{"{ module m { func f [] { true }; export stdout }; m/stdout }", "Module m does not contain a function called stdout"},
+		{"{ missing/f }", "Module missing not found"},
+		{"{ module m { let $private 1 }; println $private }", "Variable $private is not defined"},
+		{"{ module m {}; module m {} }", "Module m is already defined"},
+		{"{ export f }", "export can only be used inside a module"},
+	} {
+		_, err := NewVM().Run(c.code)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("Code %q should fail with %q got %v", c.code, c.expected, err)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gshell-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, code := range map[string]string{
+		"lib/strings.gsh": "println loading strings\nfunc shout [$s] { println $s }\nexport shout\n",
+		"cycle/a.gsh":     "import cycle/b\n",
+		"cycle/b.gsh":     "import cycle/a\n",
+		"env.gsh":         "func hello [] { println from env }\nexport hello\n",
+	} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vm := NewVM(WithSearchPath(filepath.Join(dir, "missing"), dir))
+	_, err = vm.Run(`{
+		import lib/strings
+		import lib/strings as str
+		import lib/strings
+		strings/shout hi
+		str/shout hey
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"loading strings\n",
+		"hi\n",
+		"hey\n",
+	})
+
+	for _, c := range []struct {
+		code     string
+		expected string
+	}{
+		{"{ import cycle/a }", "import cycle: " + filepath.Join(dir, "cycle", "a.gsh") + " -> " + filepath.Join(dir, "cycle", "b.gsh") + " -> " + filepath.Join(dir, "cycle", "a.gsh")},
+		{"{ import unknown }", "Module unknown not found"},
+		{"{ module strings {}; import lib/strings }", "Module strings is already defined"},
+	} {
+		_, err := NewVM(WithSearchPath(dir)).Run(c.code)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("Code %q should fail with %q got %v", c.code, c.expected, err)
+		}
+	}
+
+	defer os.Setenv(SearchPathEnv, os.Getenv(SearchPathEnv))
+	os.Setenv(SearchPathEnv, dir)
+	vm = NewVM()
+	if _, err := vm.Run("{ import env; env/hello }"); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{"from env\n"})
+}
+
+func TestErrorsPointToTheFailingCode(t *testing.T) {
+	for _, c := range []struct {
+		code     string
+		expected string
+	}{
+		{"{\n\tprintln hi\n\tfoo bar\n}", "line 3:2: Command foo not found"},
+		{"{ println hi $undefined }", "line 1:14: Variable $undefined is not defined"},
+		{"{ println \"hi $missing\" }", "line 1:15: Variable $missing is not defined"},
+		{"{ guard { true } {\n\tprintln $a\n} }", "line 2:10: Variable $a is not defined"},
+		{"{ println ok (missing 1) }", "line 1:15: Command missing not found"},
+	} {
+		vm := NewVM()
+		_, err := vm.Run(c.code)
+		var vmErr *Error
+		if !errors.As(err, &vmErr) {
+			t.Errorf("Code %q should fail with a *vm.Error got %#v", c.code, err)
+			continue
+		}
+		if err.Error() != c.expected {
+			t.Errorf("Code %q should fail with %q got %q", c.code, c.expected, err.Error())
+		}
+	}
+}
+
 func assertOutput(t *testing.T, output mailbox.Reader, values []Value) []Value {
 	items, err := extractAtLeastValues(output, len(values))
 	if err != nil {
@@ -146,17 +610,407 @@ func extractAtLeastValues(in mailbox.Reader, n int) ([]Value, error) {
 	return buf, nil
 }
 
-func TestFunctionsCannotDeclareOtherFunctions(t *testing.T) {
+func TestNestedFunctions(t *testing.T) {
 	vm := NewVM()
 	_, err := vm.Run(`{
-		func print-a-and-b [$a,$b] {
-			func internal [] {
-				true
+		func countdown [$n] {
+			func step [$i] {
+				guard { let $more (= $i > 0) } {
+					println $i
+					step (= $i - 1)
+				}
 			}
+			step $n
+		}
+		countdown 3
+
+		func greet [] { println module }
+		func shadow [] {
+			func greet [] { println inner }
+			greet
+		}
+		shadow
+		greet
+
+		func counter [$start] {
+			func next [$step] { return (= $start + $step) }
+			return $next
 		}
-		print-a-and-b 10 10
+		let $next (counter 10)
+		println ($next 5)
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"3\n", "2\n", "1\n",
+		"inner\n",
+		"module\n",
+		"15\n",
+	})
+
+	_, err = NewVM().Run(`{
+		func outer [] {
+			func inner [] { true }
+			func inner [] { false }
+		}
+		outer
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "Function inner is already defined") {
+		t.Fatalf("Redefining a nested function should fail got %v", err)
+	}
+
+	_, err = NewVM().Run(`{
+		func outer [] { func inner [] { true } }
+		outer
+		inner
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "Command inner not found") {
+		t.Fatalf("Nested functions should not leak to the module got %v", err)
+	}
+}
+
+func TestTailCalls(t *testing.T) {
+	vm := NewVM(WithMaxCallDepth(50))
+	value, err := vm.Run(`{
+		func sum [$n $acc] {
+			switch {
+				case { let $done (= $n == 0) } { return $acc }
+				else { sum (= $n - 1) (= $acc + $n) }
+			}
+		}
+		func count [$n] {
+			guard { let $more (= $n > 0) } { count (= $n - 1) }
+		}
+		count 20000
+		func is-even [$n] {
+			switch {
+				case { let $zero (= $n == 0) } { true }
+				else { is-odd (= $n - 1) }
+			}
+		}
+		func is-odd [$n] {
+			switch {
+				case { let $zero (= $n == 0) } { false }
+				else { is-even (= $n - 1) }
+			}
+		}
+		let $even (is-even 10001)
+		sum 20000 0
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != ast.NewNumber(200010000) {
+		t.Errorf("Expecting 200010000 got %v", value)
+	}
+
+	_, err = NewVM(WithMaxCallDepth(50)).Run(`{
+		func deep [$n] {
+			let $r (deep (= $n + 1))
+		}
+		deep 0
 	}`)
 	if err == nil {
-		t.Fatal("Gshell should not support internal function definitions (yet!)")
+		t.Fatal("Unbounded recursion should fail")
+	}
+	for _, expected := range []string{
+		"stack overflow: more than 50 nested calls\n\tmain/deep\n",
+		"\n\t... 41 more",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Error should contain %q got %v", expected, err)
+		}
+	}
+	if strings.Contains(err.Error(), "Function deep failed") {
+		t.Errorf("Stack overflow should not be wrapped by every frame got %v", err)
+	}
+
+	_, err = NewVM(WithMaxCallDepth(3)).Run(`{
+		func deep [$n] { let $r (deep (= $n + 1)) }
+		func bounce [$n] { deep $n }
+		func start [] { let $r (bounce 0) }
+		start
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "\n\tmain/deep (tail call)\n\tmain/start") {
+		t.Errorf("Frames replaced by tail calls should be marked got %v", err)
+	}
+}
+
+func TestTailCallArgumentsUseTheCallerModule(t *testing.T) {
+	vm := NewVM()
+	value, err := vm.Run(`{
+		module m {
+			func h [] { true }
+			func g [$x] { return $x }
+			func f [] { g (h) }
+			export f
+		}
+		m/f
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != trueSym {
+		t.Errorf("Expecting true got %v", value)
+	}
+}
+
+func TestTryCatch(t *testing.T) {
+	vm := NewVM()
+	value, err := vm.Run(`{
+		try { fail boom } catch $e { println $e }
+		try { fail "no such user" --kind=not-found } catch $e { println $e }
+		func div [$a $b] { return (= $a / $b) }
+		try { div 1 0 } catch $e { println $e }
+		try { println $missing } catch $e { println $e } finally { println cleanup }
+		try { println ok } finally { println cleanup }
+		func early [] {
+			try { return early } catch $e { println caught } finally { println cleanup }
+			late
+		}
+		println (early)
+		try { try { fail inner } finally { println cleanup } } catch $e { println again }
+		try { fail first } catch $e { fail $e }
+	}`)
+	if err == nil || !strings.Contains(err.Error(), "first") {
+		t.Fatalf("Failing inside catch should abort the script got %v %v", value, err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		`%[ command "fail" kind "user" message "boom" ]` + "\n",
+		`%[ command "fail" kind "not-found" message "no such user" ]` + "\n",
+		`%[ command "return" kind "runtime" message "division by zero" ]` + "\n",
+		`%[ command "println" kind "undefined-variable" message "Variable \$missing is not defined" ]` + "\n",
+		"cleanup\n",
+		"ok\n",
+		"cleanup\n",
+		"cleanup\n",
+		"early\n",
+		"cleanup\n",
+		"again\n",
+	})
+
+	value, err = NewVM().Run("{ try { fail boom } catch $e { let $r recovered } }")
+	if err != nil || value != ast.MustNewSymbol("recovered") {
+		t.Errorf("try should return the value of the catch block got %v %v", value, err)
+	}
+
+	for _, code := range []string{
+		"{ try { true } }",
+		"{ try { true } catch { true } }",
+		"{ try { true } finally }",
+		"{ try { true } catch $e { true } other }",
+		"{ fail }",
+	} {
+		if _, err := NewVM().Run(code); err == nil {
+			t.Errorf("Code %q should fail", code)
+		}
+	}
+}
+
+func TestStackTraces(t *testing.T) {
+	_, err := NewVM().Run(`{
+		module greeter {
+			func greet [$name] { fail "cannot greet $name" }
+			export greet
+		}
+		func welcome [$name] {
+			let $msg (greeter/greet $name)
+			println $msg
+		}
+		println start
+		welcome bob
+	}`)
+	var vmErr *Error
+	if !errors.As(err, &vmErr) {
+		t.Fatalf("Run should fail with a *vm.Error got %#v", err)
+	}
+	expected := "Function welcome failed: Function greet failed: line 3:25: cannot greet bob"
+	if vmErr.Error() != expected {
+		t.Errorf("Expecting %q got %q", expected, vmErr.Error())
+	}
+	if vmErr.Span.Start.Line != 3 || vmErr.Command != failSym {
+		t.Errorf("Error should point to the fail command got %v %v", vmErr.Span, vmErr.Command)
+	}
+	expected += `
+
+trace:
+greeter/greet
+	line 3:25 fail
+main/welcome
+	line 7:14 greeter/greet
+module main
+	line 11:3 welcome`
+	if actual := fmt.Sprintf("%+v", err); actual != expected {
+		t.Errorf("Expecting trace\n%v\ngot\n%v", expected, actual)
+	}
+
+	_, err = NewVM().Run(`{
+		let $f (fn [] { fail nope })
+		$f
+	}`)
+	if actual := fmt.Sprintf("%+v", err); !strings.HasSuffix(actual, "module main\n\tline 3:3 $f") {
+		t.Errorf("Frames should keep the $ of the command got\n%v", actual)
+	}
+
+	_, err = NewVM(WithMaxCallDepth(15)).Run(`{
+		func deep [$n] { let $r (deep (= $n + 1)) }
+		deep 0
+	}`)
+	actual := fmt.Sprintf("%+v", err)
+	if strings.Count(actual, "main/deep") != maxTraceFrames {
+		t.Errorf("Frames should be printed once got\n%v", actual)
+	}
+	if !strings.HasSuffix(actual, "\n... 6 more") {
+		t.Errorf("Trace should be limited to the innermost frames got\n%v", actual)
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewVM().RunContext(cancelled, "{ println never }"); !errors.Is(err, context.Canceled) {
+		t.Errorf("A cancelled context should stop the script got %v", err)
+	}
+
+	for _, code := range []string{
+		"{ func spin [] { spin }; spin }",
+		"{ loop i from 1 to 1000000000000 {} }",
+		"{ try { func spin [] { spin }; spin } catch $e { println caught } }",
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		_, err := NewVM().RunContext(ctx, code)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Code %q should stop when the deadline expires got %v", code, err)
+		}
+	}
+
+	vm := NewVM()
+	value, err := vm.Run(`{
+		func spin [] { spin }
+		try { timeout "10ms" { spin } } catch $e { println $e }
+		timeout 1 { let $r done }
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != ast.MustNewSymbol("done") {
+		t.Errorf("timeout should return the value of its body got %v", value)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		`%[ command "timeout" kind "timeout" message "Block did not finish within 10ms: context deadline exceeded" ]` + "\n",
+	})
+
+	if _, err := NewVM().Run("{ timeout soon { true } }"); err == nil || !strings.Contains(err.Error(), "Invalid duration soon") {
+		t.Errorf("timeout should reject invalid durations got %v", err)
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	for _, c := range []struct {
+		opts    []Option
+		code    string
+		counter BudgetCounter
+	}{
+		{[]Option{WithMaxCommands(100)}, "{ func spin [] { spin }; spin }", BudgetCommands},
+		{[]Option{WithMaxCommands(100)}, "{ loop i from 1 to 1000000000000 {} }", BudgetCommands},
+		{[]Option{WithMaxCallDepth(10)}, "{ func deep [] { let $r (deep) }; deep }", BudgetCallDepth},
+		{[]Option{WithMaxListSize(3)}, "{ let $l [1 2 3 4] }", BudgetListSize},
+		{[]Option{WithMaxListSize(3)}, "{ let $l [1 2]; let $m [$l... $l...] }", BudgetListSize},
+		{[]Option{WithMaxListSize(3)}, "{ loop i from 1 to 10 { true } }", BudgetListSize},
+		{[]Option{WithMaxListSize(3)}, "{ let $m %[ a 1 b 2 c 3 d 4 e 5 ] }", BudgetListSize},
+		{[]Option{WithWallClockLimit(10 * time.Millisecond)}, "{ loop i from 1 to 1000000000000 {} }", BudgetWallClock},
+		{[]Option{WithMaxCommands(100)}, "{ func spin [] { spin }; try { spin } catch $e { println caught } }", BudgetCommands},
+	} {
+		_, err := NewVM(c.opts...).Run(c.code)
+		var budgetErr *BudgetError
+		if !errors.Is(err, ErrBudgetExceeded) || !errors.As(err, &budgetErr) {
+			t.Errorf("Code %q should exceed its budget got %v", c.code, err)
+			continue
+		}
+		if budgetErr.Counter != c.counter {
+			t.Errorf("Code %q should exceed the %v budget got %v", c.code, c.counter, budgetErr.Counter)
+		}
+	}
+
+	// counters are reset by every run
+	vm := NewVM(WithMaxCommands(3))
+	for i := 0; i < 3; i++ {
+		if _, err := vm.Run("{ true; true; true }"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	for _, c := range []struct {
+		opts       []Option
+		code       string
+		capability Capability
+	}{
+		{[]Option{Sandboxed()}, "{ import lib/strings }", CapFilesystem},
+		{[]Option{WithoutCapabilities(CapOutput)}, "{ println hi }", CapOutput},
+		{[]Option{WithBuiltins(letSym)}, "{ let $a 1; println $a }", ""},
+		{[]Option{WithBuiltins(letSym, funcSym)}, "{ func f [] { loop i from 1 to 2 {} }; f }", ""},
+		{[]Option{WithBuiltins(printlnSym), WithoutCapabilities(CapOutput)}, "{ println hi }", CapOutput},
+	} {
+		_, err := NewVM(c.opts...).Run(c.code)
+		var permErr *PermissionError
+		if !errors.Is(err, ErrPermissionDenied) || !errors.As(err, &permErr) {
+			t.Errorf("Code %q should be denied got %v", c.code, err)
+			continue
+		}
+		if permErr.Capability != c.capability {
+			t.Errorf("Code %q should require %v got %v", c.code, c.capability, permErr.Capability)
+		}
+	}
+
+	vm := NewVM(Sandboxed())
+	_, err := vm.Run(`{
+		func greet [$name] { println "hello $name" }
+		greet sandbox
+		try { import lib/strings } catch $e { println $e }
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{
+		"hello sandbox\n",
+		`%[ command "import" kind "permission" message "permission denied: command import requires the filesystem capability" ]` + "\n",
+	})
+
+	if _, err := NewVM(WithBuiltins(letSym)).Run("{ unknown }"); err == nil || !strings.Contains(err.Error(), "Command unknown not found") {
+		t.Errorf("Commands which are not builtins should not be reported as denied got %v", err)
+	}
+	if _, err := NewVM(WithBuiltins(letSym)).Run("{ println hi }"); err == nil || !strings.Contains(err.Error(), "command println is not in the allowed builtin list") {
+		t.Errorf("Builtins left out by WithBuiltins should say so got %v", err)
+	}
+	if _, err := NewVM(WithBuiltins(letSym, ast.MustNewSymbol("prinltn"))).Run("{ let $a 1 }"); err == nil || !strings.Contains(err.Error(), "Builtin prinltn given to WithBuiltins does not exist") {
+		t.Errorf("Unknown builtins given to WithBuiltins should be rejected got %v", err)
+	}
+}
+
+func TestRunPrograms(t *testing.T) {
+	vm := NewVM()
+	_, err := vm.RunReader(strings.NewReader("#!/usr/bin/env gshell\nlet $a 1\nprintln $a; println done\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, vm.Stdout(), []Value{"1\n", "done\n"})
+
+	file, err := ioutil.TempFile("", "gshell-*.gsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	io.WriteString(file, "println from-file\nunknown-command\n")
+	file.Close()
+
+	_, err = vm.RunFile(file.Name())
+	expected := file.Name() + ":2:1: Command unknown-command not found"
+	if err == nil || err.Error() != expected {
+		t.Errorf("RunFile should fail with %q got %v", expected, err)
 	}
+	assertOutput(t, vm.Stdout(), []Value{"from-file\n"})
 }